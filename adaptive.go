@@ -0,0 +1,81 @@
+package cachedrander
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	// A page that is consumed in less than adaptShort is too small and a
+	// page that lasts longer than adaptLong is larger than needed.
+	adaptShort = 100 * time.Millisecond
+	adaptLong  = 10 * time.Second
+)
+
+// An adaptive holds the bounds set by WithAdaptiveSize.
+type adaptive struct {
+	min, max int
+}
+
+// WithAdaptiveSize causes the page size to be adjusted, within the bounds min
+// and max, as the CachedReader is used.  The size is doubled when a page is
+// consumed in under 100ms, or when a Read had to wait for the background filler
+// (see WithBackgroundFill), and halved when a page lasts more than 10 seconds.
+// This lets the cache follow daily traffic patterns without operator
+// intervention.  Like Resize, a new size takes effect as pages are loaded.  The
+// size passed to New is limited to the bounds, which are rounded up as
+// described by New.  New returns an error wrapping ErrInvalidSize if min is not
+// positive or max is less than min.
+func WithAdaptiveSize(min, max int) Option {
+	return func(r *CachedReader) {
+		if min <= 0 || max < min {
+			r.err = fmt.Errorf("%w: adaptive bounds %d to %d", ErrInvalidSize, min, max)
+			return
+		}
+		r.adaptive = &adaptive{min: min, max: max}
+	}
+}
+
+// bound limits size to the bounds of a, which are first rounded up by
+// r.checkSize.  It is called by New.
+func (a *adaptive) bound(r *CachedReader, size int) (int, error) {
+	var err error
+	if a.min, err = r.checkSize(a.min); err != nil {
+		return 0, err
+	}
+	if a.max, err = r.checkSize(a.max); err != nil {
+		return 0, err
+	}
+	return max(a.min, min(size, a.max)), nil
+}
+
+// adapt adjusts the page size based on how p, which has just been retired
+// after being the current page, was used.
+func (r *CachedReader) adapt(p *page) {
+	a := r.adaptive
+	if a == nil {
+		return
+	}
+	size := int(p.size)
+	switch d := time.Since(p.start); {
+	case d < adaptShort,
+		r.refill != nil && r.blocked.Load() != p.blocked:
+		size *= 2
+	case d > adaptLong:
+		size /= 2
+	default:
+		return
+	}
+	// Rounding keeps the size a multiple of Max, and of 16 if the pages are
+	// preformed.  The bounds are already rounded.  checkSize only fails if
+	// Max was raised above a.min after New (which SetMaxRead prevents), in
+	// which case p's size, which was valid, is kept.
+	size, err := r.checkSize(max(a.min, min(size, a.max)))
+	if err != nil || size == int(p.size) {
+		return
+	}
+	if old := r.size.Swap(uint64(size)); old != uint64(size) {
+		r.log(slog.LevelDebug, "cachedrander: page size changed", "from", old, "to", size)
+	}
+}
@@ -0,0 +1,78 @@
+package cachedrander
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveSize(t *testing.T) {
+	for _, bounds := range [][2]int{{0, 16}, {32, 16}} {
+		if _, err := New(&gen{size: 256}, 160, WithAdaptiveSize(bounds[0], bounds[1])); !errors.Is(err, ErrInvalidSize) {
+			t.Errorf("bounds %v got error %v, want %v", bounds, err, ErrInvalidSize)
+		}
+	}
+
+	r, err := New(&gen{size: 256}, 16, WithAdaptiveSize(100, 1600))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.size.Load(); got != 112 {
+		t.Errorf("got initial size %d, want 112", got)
+	}
+
+	// Consuming pages quickly grows them to the maximum.
+	var buf [16]byte
+	for i := 0; i < 1000; i++ {
+		if _, err := r.Read(buf[:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := r.size.Load(); got != 1600 {
+		t.Errorf("got size %d, want 1600", got)
+	}
+
+	// Pages that last a long time shrink to the minimum.
+	for i := 0; i < 10; i++ {
+		p := r.cur.Load()
+		p.start = time.Now().Add(-time.Minute)
+		p.offset.Store(p.size + 1)
+		if err := r.fill(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := r.size.Load(); got != 112 {
+		t.Errorf("got size %d, want 112", got)
+	}
+	if p := r.cur.Load(); p.size != 112 {
+		t.Errorf("got page size %d, want 112", p.size)
+	}
+
+	// Pages used at a moderate pace keep their size.
+	p := r.cur.Load()
+	p.start = time.Now().Add(-time.Second)
+	r.adapt(p)
+	if got := r.size.Load(); got != 112 {
+		t.Errorf("got size %d, want 112", got)
+	}
+}
+
+func TestAdaptiveMaxRead(t *testing.T) {
+	r, err := New(&gen{size: 256}, 128, WithAdaptiveSize(64, 4096))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetMaxRead(128); !errors.Is(err, ErrInvalidSize) {
+		t.Errorf("SetMaxRead above the minimum got error %v, want %v", err, ErrInvalidSize)
+	}
+
+	// Even if Max is raised directly, a slow page does not shrink the size
+	// below it.
+	r.Max = 128
+	p := r.cur.Load()
+	p.start = time.Now().Add(-time.Minute)
+	r.adapt(p)
+	if got := r.size.Load(); got != 128 {
+		t.Errorf("got size %d, want 128", got)
+	}
+}
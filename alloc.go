@@ -0,0 +1,55 @@
+package cachedrander
+
+import "log/slog"
+
+// An Allocator allocates the memory that holds a CachedReader's pages, such as
+// memory that is locked into RAM and guarded against overflows.  See the
+// memguardpages package for an implementation using github.com/awnumar/memguard.
+type Allocator interface {
+	// Alloc returns size bytes of memory.
+	Alloc(size int) ([]byte, error)
+
+	// Free releases memory returned by Alloc.  The memory must not be used
+	// after Free returns.  An error returned by Free is logged (see
+	// WithLogger).
+	Free(b []byte) error
+}
+
+// WithAllocator causes the CachedReader to allocate its pages with a rather than
+// on the Go heap.  Pages are freed when they are resized (see Resize) and by
+// Close.  A page is never freed while a Read is copying from it.
+func WithAllocator(a Allocator) Option {
+	return func(r *CachedReader) {
+		r.alloc = a
+	}
+}
+
+// allocate returns size bytes of memory for a page.
+func (r *CachedReader) allocate(size int) ([]byte, error) {
+	if r.alloc == nil {
+		return make([]byte, size), nil
+	}
+	return r.alloc.Alloc(size)
+}
+
+// free releases the memory of a page once its buffer has been invalidated.
+func (r *CachedReader) free(b []byte) {
+	if r.alloc != nil && b != nil {
+		if err := r.alloc.Free(b); err != nil {
+			r.log(slog.LevelWarn, "cachedrander: freeing page failed", "error", err)
+		}
+	}
+}
+
+// freeAll frees the memory of all of r's pages, which must not be in use.
+func (r *CachedReader) freeAll() {
+	if r.alloc == nil {
+		return
+	}
+	for _, b := range r.bufs {
+		if b != nil {
+			r.free(b.data)
+			b.data = nil
+		}
+	}
+}
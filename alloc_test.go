@@ -0,0 +1,81 @@
+package cachedrander
+
+import (
+	"errors"
+	"testing"
+)
+
+// testAllocator tracks the memory it has allocated.
+type testAllocator struct {
+	live  map[*byte]int
+	fail  bool
+	frees int
+}
+
+func (a *testAllocator) Alloc(size int) ([]byte, error) {
+	if a.fail {
+		return nil, errors.New("out of memory")
+	}
+	b := make([]byte, size)
+	a.live[&b[0]] = size
+	return b, nil
+}
+
+func (a *testAllocator) Free(b []byte) error {
+	if _, ok := a.live[&b[0]]; !ok {
+		panic("freeing memory that was not allocated")
+	}
+	delete(a.live, &b[0])
+	a.frees++
+	return nil
+}
+
+func TestAllocator(t *testing.T) {
+	a := &testAllocator{live: map[*byte]int{}}
+	r, err := New(&gen{size: 17}, 64, WithAllocator(a))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.live) != 2 {
+		t.Errorf("got %d pages allocated, want 2", len(a.live))
+	}
+	for _, b := range r.bufs {
+		if a.live[&b.data[0]] != 64 {
+			t.Errorf("page not allocated by the allocator")
+		}
+	}
+	r.Max = 8
+	checkSequential(t, r)
+	if _, err := r.Read16(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Resize(128); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reseed(); err != nil {
+		t.Fatal(err)
+	}
+	if a.frees != 1 || len(a.live) != 2 {
+		t.Errorf("after Resize got %d frees and %d pages, want 1 and 2", a.frees, len(a.live))
+	}
+	a.fail = true
+	if err := r.Resize(256); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reseed(); err == nil {
+		t.Error("failed allocation did not return an error")
+	}
+
+	r.Close()
+	if len(a.live) != 0 {
+		t.Errorf("Close left %d pages allocated", len(a.live))
+	}
+	a.fail = false
+	if _, err := New(&flakyReader{fail: true}, 64, WithAllocator(a)); err == nil {
+		t.Fatal("New did not fail")
+	}
+	if len(a.live) != 0 {
+		t.Errorf("failed New left %d pages allocated", len(a.live))
+	}
+}
@@ -1,105 +1,960 @@
 // Package cachedrander provides a reader designed to cache random data for the
 // creation of random UUIDs.  Using rand.Reader as the source of random data
 // (the default for github.com/google/uuid) requires a mutex operation per newly
-// minted version 4 (random) UUID.  This package typically only requires a
-// single atomic.AddUint64 per newly minted UUID.
+// minted version 4 (random) UUID.  This package typically only requires three
+// atomic additions per newly minted UUID: one to reserve its data and two to
+// pin the page's buffer while the data is copied.
 //
 // This package works by having two pages of cached random data.  The first page
 // is read when the CachedReader is created.  Once that page has been exhausted
-// Read calls will block on a mutex while the second page is being loaded.
+// Read calls will block on a mutex while the second page is being loaded.  The
+// WithBackgroundFill option moves loading of the second page to a dedicated
+// goroutine so Read calls rarely need to block.  The WithPageCount option
+// increases the number of pages in the ring so the background filler can keep
+// several pages loaded ahead of a bursty workload.  The WithWarmStandby option
+// combines the two so that, in the steady state, Read only blocks when the
+// source cannot keep up with sustained demand.
 //
-// This package has a theoretical race condition:
+// The current page is described by an immutable page structure, published
+// with an atomic pointer, holding the page's generation and a 64 bit counter of
+// the bytes reserved from it.  A new page structure is published for each
+// generation so neither the generation nor the counter can overflow.
 //
-// Caller A reads the index of its data in the current page and is prempted.
-// Prior to resuming a sufficent number of calls to Read are made to exhaust the
-// current page and the next loaded page.  It is now possible for caller A to
-// return the same data as another caller.
+// Caller A may reserve its data in the current page and be prempted.  Prior to
+// resuming a sufficent number of calls to Read may be made to exhaust the
+// current page and the next loaded page, causing A's page to be reloaded.  To
+// prevent A from returning the same data as another caller each page's buffer
+// is stamped with the generation of the data it holds.  Before copying its
+// data A pins the buffer, which keeps it from being reloaded until A unpins it,
+// and verifies the buffer still holds the generation it started with.  If not,
+// A unpins the buffer and tries again with the new current page.
 //
-// to mitigate this condition the CachedReader should use a sufficiently large
-// cache that the probability of this happening is essentially 0.
+// The package supports 32 bit platforms, such as 386 and arm, as well as 64 bit
+// platforms.  The 64 bit counters it updates atomically use the types of
+// sync/atomic, such as atomic.Uint64, which are always 64 bit aligned.
 package cachedrander
 
 import (
+	"context"
 	"crypto/rand"
+	"errors"
+	"fmt"
 	"io"
+	"log/slog"
+	"runtime"
 	"sync"
 	"sync/atomic"
+	"time"
+
+	"golang.org/x/sys/cpu"
 )
 
+// ErrClosed is returned by Read after the CachedReader has been closed.
+var ErrClosed = errors.New("cachedrander: reader is closed")
+
+// ErrInvalidSize is returned, wrapped with details, by New when the requested
+// cache size cannot be used.
+var ErrInvalidSize = errors.New("cachedrander: invalid cache size")
+
 // A CachedReader caches chunks of data from a reader and then provides that
 // data to calls to its Read method.
 //
 // The Max value determines the maximum size read that will be honored.  This
 // defaults to 16 (the size of a UUID).  Max should only be set prior to the
-// first Read of the CachedReader.  Max should be multiple times smaller than
-// the size of the cache.
+// first Read of the CachedReader; use SetMaxRead to change it safely once the
+// CachedReader is in use.  Max should be multiple times smaller than the size
+// of the cache.
 type CachedReader struct {
 	Max int
 
-	mu    sync.Mutex
-	pages [2][]byte
-	size  uint64
-	index uint64
-	r     io.Reader
+	// The fields up to mu are read by every Read and are not written once
+	// the CachedReader is in use, other than by SetMaxRead and when a new
+	// page becomes current.  The fields that are written by Reads or when
+	// pages are loaded are kept on separate cache lines, by padding, so
+	// they do not slow down Reads on other CPUs (false sharing).
+	maxRead atomic.Int64 // set by SetMaxRead, overrides Max if not 0
+	cur     atomic.Pointer[page]
+
+	fillAt  float64       // fraction of a page that triggers a background fill
+	warm    bool          // keep every standby page loaded
+	predict *predictor    // set by WithPredictiveFill
+	refill  chan struct{} // nil unless WithBackgroundFill was used
+	done    chan struct{} // closed by Close to stop goroutines
+	// wraps are the functions used by options to wrap the source, in the
+	// order they were applied.
+	wraps []func(io.Reader) io.Reader
+
+	stride int       // bytes reserved at a time by Read, if not 0
+	spans  sync.Pool // *Local holding reserved spans of stride bytes
+
+	fillTimeout time.Duration  // set by WithFillTimeout
+	retries     int            // set by WithRetry
+	backoff     time.Duration  // set by WithRetry
+	fallback    bool           // set by WithFallback
+	breaker     *breaker       // set by WithCircuitBreaker
+	onError     func(error)    // set by WithErrorHandler
+	slowFill    time.Duration  // set by WithSlowFillThreshold
+	onSlowFill  func(FillInfo) // set by WithSlowFillThreshold
+	logger      *slog.Logger   // set by WithLogger
+
+	nonces     *nonceTracker // set by WithNonceTracking
+	directKeys bool          // GenerateKey reads from the source
+	preformed  bool          // pages are formatted as version 4 UUIDs
+	lazy       bool          // set by WithLazyInit
+	health     *healthTests  // set by WithHealthTests
+	stuck      bool          // set by WithStuckDetection
+	sanity     bool          // set by WithSanityCheck
+	dups       *dupDetector  // nil unless built with cachedranderdebug
+	adaptive   *adaptive     // set by WithAdaptiveSize
+	zeroize    bool          // set by WithZeroizeOnRead
+	alloc      Allocator     // set by WithAllocator
+	encrypt    bool          // set by WithEncryptedPages
+	maxAge     time.Duration // set by WithMaxPageAge
+	recorder   *recorder     // set by WithRecorder
+
+	reseedEvery uint64 // set by WithReseedInterval
+	workers     int    // set by WithParallelFill
+
+	metrics Metrics
+
+	vmgenID       func() ([]byte, error)
+	vmgenInterval time.Duration
+
+	fullReads bool
+
+	detectFork bool
+
+	err error // set by an Option that was passed invalid arguments
+
+	_       cpu.CacheLinePad
+	mu      sync.Mutex
+	loads   loadSignal                // lets Reads wait for a load without acquiring mu
+	filling atomic.Pointer[asyncCall] // the fill run for await, if any
+	pinging atomic.Pointer[asyncCall] // the probe run by Ping, if any
+	bufs    []*buffer                 // the ring of page buffers
+	size    atomic.Uint64             // the size of newly loaded pages
+	r       io.Reader                 // the source, wrapped by the options
+	src     io.Reader                 // the unwrapped source, written holding both mu and statMu
+	closed  bool                      // written holding both mu and statMu
+
+	seedServed uint64 // bytes served when the DRBGs were last reseeded
+	drbgs      []drbg // the DRBGs among the wrapped sources
+
+	stuckPrev []byte // the start of the last page loaded, if r.stuck
+	stuckGen  uint64 // the generation of stuckPrev
+
+	// Statistics reported by Stats.  The atomic counters are updated
+	// without holding mu, the rest are protected by statMu, which is
+	// never held while reading the source so Stats and Healthy do not
+	// wait for a hung source.  The state of the breaker is written
+	// holding both mu and statMu.
+	statMu   sync.Mutex
+	fills    uint64
+	fillTime time.Duration
+	fillHist Histogram
+	wasted   uint64 // bytes discarded by discard
+	lastErr  error  // the result of the most recent load
+
+	_         cpu.CacheLinePad
+	served    atomic.Uint64 // bytes served other than from the current page
+	blocked   atomic.Uint64 // Reads that called fill
+	skipped   atomic.Uint64 // reserved bytes that were not served
+	fallbacks atomic.Uint64 // reads served directly from crypto/rand
+	discards  atomic.Uint64 // number of calls to discard
+	forkGen   atomic.Uint64 // forkGeneration when last seeded
+
+	blockedHist atomicHistogram // how long Reads that called fill waited
+	_           cpu.CacheLinePad
+}
+
+// An Option configures a CachedReader created by New.
+type Option func(*CachedReader)
+
+// WithBackgroundFill causes a dedicated goroutine to load the standby page as
+// soon as fraction (e.g., 0.75) of the current page has been consumed.  This
+// makes it unlikely that a Read will ever need to wait on the source.  Values
+// of fraction outside the range (0, 1] are treated as 1.  The goroutine is
+// stopped by calling Close.
+func WithBackgroundFill(fraction float64) Option {
+	return func(r *CachedReader) {
+		if fraction <= 0 || fraction > 1 {
+			fraction = 1
+		}
+		r.fillAt = fraction
+		r.refill = make(chan struct{}, 1)
+	}
+}
+
+// WithPageCount sets the number of pages in the ring to n (the default is 2).
+// Values of n less than 2 are treated as 2.  Additional pages are only loaded
+// ahead of time by the background filler, so WithPageCount is normally used
+// along with WithBackgroundFill.
+func WithPageCount(n int) Option {
+	return func(r *CachedReader) {
+		if n < 2 {
+			n = 2
+		}
+		r.bufs = make([]*buffer, n)
+	}
+}
+
+// WithWarmStandby starts a background filler that keeps every standby page
+// loaded at all times, reloading a page as soon as it is retired.  At least
+// three pages are used (more if requested with WithPageCount) so the current
+// page, a page being reloaded, and at least one loaded standby page may exist at
+// once.  New loads all the pages before returning.
+func WithWarmStandby() Option {
+	return func(r *CachedReader) {
+		r.warm = true
+		r.fillAt = 0
+		r.refill = make(chan struct{}, 1)
+	}
+}
+
+// wrap arranges for the source of r to be wrapped with f.  It is called by
+// Options that transform the source's data.
+func (r *CachedReader) wrap(f func(io.Reader) io.Reader) {
+	r.wraps = append(r.wraps, f)
+}
+
+// source returns src wrapped as requested by the options, in the order they
+// were given, and records the DRBGs among them.  The health tests are always
+// applied to src itself.  src is also kept in r.src, where it is serialized
+// with the reads of the wrappers unless WithParallelFill requires it to be
+// safe for concurrent use.
+func (r *CachedReader) source(src io.Reader) io.Reader {
+	if r.workers < 2 {
+		src = &lockedReader{r: src}
+	}
+	r.statMu.Lock()
+	r.src = src
+	r.statMu.Unlock()
+	if r.health != nil {
+		src = &healthReader{r: src, t: r.health}
+	}
+	r.drbgs = nil
+	for _, f := range r.wraps {
+		src = f(src)
+		if d, ok := src.(drbg); ok {
+			r.drbgs = append(r.drbgs, d)
+		}
+	}
+	if r.workers > 1 && (r.health != nil || len(r.wraps) > 0) {
+		// The wrappers are not safe for concurrent use.
+		src = &lockedReader{r: src}
+	}
+	return src
+}
+
+// WithMax sets the initial value of Max.  It is primarily useful with readers,
+// such as a ShardedReader, that do not expose their CachedReaders.
+func WithMax(n int) Option {
+	return func(r *CachedReader) {
+		r.Max = n
+	}
 }
 
 // NewUUIDReader returns a CachedReader that caches n UUID's worth of data from
 // rand.Reader at a time.  The value of n should be sufficiently large to
-// prevent the theoretical race conditioned mentioned above (e.g., 100 or 1000)
-func NewUUIDReader(n int) (*CachedReader, error) {
-	return New(rand.Reader, n*16)
+// prevent the theoretical race conditioned mentioned above (e.g., 100 or 1000).
+// EstimateRaceProbability can be used to choose n for a given workload.
+func NewUUIDReader(n int, opts ...Option) (*CachedReader, error) {
+	return New(rand.Reader, n*16, opts...)
 }
 
 // New returns a new CachedReader that caches size bytes from r at a time.  An
 // error is returned if filling the initial cache from r returns an error.
-func New(r io.Reader, size int) (*CachedReader, error) {
+//
+// The size is rounded up to a multiple of Max so that Reads of Max bytes never
+// straddle the end of a page.  New returns an error wrapping ErrInvalidSize if
+// size or Max is not positive or size is smaller than Max.
+func New(r io.Reader, size int, opts ...Option) (*CachedReader, error) {
 	nr := &CachedReader{
-		Max:   16,
-		size:  uint64(size),
-		pages: [2][]byte{make([]byte, size), make([]byte, size)},
-		r:     r,
+		Max:      16,
+		r:        r,
+		slowFill: defaultSlowFill,
+		dups:     newDupDetector(),
+	}
+	for _, opt := range opts {
+		opt(nr)
+	}
+	if nr.err != nil {
+		return nil, nr.err
+	}
+	if nr.Max <= 0 {
+		return nil, fmt.Errorf("%w: Max %d is not positive", ErrInvalidSize, nr.Max)
+	}
+	nr.r = nr.source(r)
+	nr.dups.setSource(r)
+	size, err := nr.checkSize(size)
+	if err != nil {
+		return nil, err
+	}
+	if nr.adaptive != nil {
+		if size, err = nr.adaptive.bound(nr, size); err != nil {
+			return nil, err
+		}
+	}
+	nr.size.Store(uint64(size))
+	if nr.bufs == nil {
+		nr.bufs = make([]*buffer, 2)
+	}
+	if nr.warm && len(nr.bufs) < 3 {
+		nr.bufs = make([]*buffer, 3)
 	}
-	// Fill the first cache buffer
-	if _, err := io.ReadFull(r, nr.pages[0]); err != nil {
+	for i := range nr.bufs {
+		data, err := nr.allocate(size)
+		if err != nil {
+			nr.freeAll()
+			return nil, err
+		}
+		nr.bufs[i] = &buffer{data: data}
+		nr.bufs[i].stamp.Store(noGen)
+	}
+	if nr.lazy {
+		nr.cur.Store(nr.unloadedPage())
+	} else if err := nr.load(0); err != nil {
+		nr.freeAll()
 		return nil, err
+	} else {
+		nr.cur.Store(nr.newPage(nr.bufs[0], 0))
+	}
+	if nr.warm && !nr.lazy {
+		if err := nr.loadPages(1, len(nr.bufs)-1); err != nil {
+			nr.freeAll()
+			return nil, err
+		}
+	}
+	if nr.refill != nil || nr.vmgenID != nil || nr.maxAge > 0 {
+		nr.done = make(chan struct{})
+	}
+	if nr.refill != nil {
+		go nr.filler()
+	}
+	if nr.vmgenID != nil {
+		id, _ := nr.vmgenID()
+		go nr.watchVMGenID(id)
+	}
+	if nr.maxAge > 0 {
+		go nr.watchAge()
 	}
 	return nr, nil
 }
 
-const (
-	indexBits = 63
-	indexMask = (1 << indexBits) - 1
-)
+// checkSize returns size rounded up as described by New, or an error wrapping
+// ErrInvalidSize if size cannot be used.
+func (r *CachedReader) checkSize(size int) (int, error) {
+	switch {
+	case size <= 0:
+		return 0, fmt.Errorf("%w: %d is not positive", ErrInvalidSize, size)
+	case size < r.maxLen():
+		return 0, fmt.Errorf("%w: %d is smaller than Max (%d)", ErrInvalidSize, size, r.maxLen())
+	case r.maxLen() > 0 && size%r.maxLen() != 0:
+		size += r.maxLen() - size%r.maxLen()
+	}
+	if r.preformed && size%16 != 0 {
+		size += 16 - size%16
+	}
+	return size, nil
+}
+
+// Resize changes the size of the cache to size bytes, rounded up as described
+// by New.  The new size takes effect as pages are loaded, so the current page
+// and any loaded standby pages keep their size.  Resize allows the trade off
+// between memory use and how often the source is read to be tuned at run time.
+// An error wrapping ErrInvalidSize is returned if size cannot be used.
+func (r *CachedReader) Resize(size int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrClosed
+	}
+	size, err := r.checkSize(size)
+	if err != nil {
+		return err
+	}
+	r.size.Store(uint64(size))
+	return nil
+}
+
+// SetMaxRead sets the maximum size read that will be honored to n.  Unlike
+// setting Max, SetMaxRead may be called concurrently with Reads.  Pages are not
+// resized, so subsequent Reads of n bytes may straddle the end of a page and
+// return short reads.  An error wrapping ErrInvalidSize is returned if n is not
+// positive or is larger than the cache, or, with WithAdaptiveSize, than the
+// smallest size the cache may shrink to.
+func (r *CachedReader) SetMaxRead(n int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch {
+	case n <= 0:
+		return fmt.Errorf("%w: Max %d is not positive", ErrInvalidSize, n)
+	case uint64(n) > r.size.Load():
+		return fmt.Errorf("%w: Max %d is larger than the cache (%d)", ErrInvalidSize, n, r.size.Load())
+	case r.adaptive != nil && n > r.adaptive.min:
+		return fmt.Errorf("%w: Max %d is larger than the adaptive minimum (%d)", ErrInvalidSize, n, r.adaptive.min)
+	}
+	r.maxRead.Store(int64(n))
+	return nil
+}
+
+// maxLen returns the maximum size read that will be honored.
+func (r *CachedReader) maxLen() int {
+	if m := r.maxRead.Load(); m != 0 {
+		return int(m)
+	}
+	return r.Max
+}
+
+// Close stops the background filler, if any, overwrites all cached data with
+// zeros, and frees pages allocated by WithAllocator.  Subsequent calls to Read
+// return ErrClosed.  Close always returns nil.
+func (r *CachedReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.statMu.Lock()
+	r.closed = true
+	r.statMu.Unlock()
+	if r.done != nil {
+		close(r.done)
+	}
+	r.discard()
+	r.freeAll()
+	return nil
+}
+
+// Reseed discards all cached data and immediately reloads the current page
+// from the source.  A DRBG wrapping the source, such as the one used by
+// WithChaCha20 or WithCTRDRBG, first reads a new seed, so neither the cached
+// data nor the DRBG's future output is repeated.  Reseed should be called when
+// the cached data must be considered compromised, such as after a fork or when
+// a virtual machine has been cloned.  If the source returns an error then no
+// cached data is served until a subsequent Read is able to load a page.
+func (r *CachedReader) Reseed() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrClosed
+	}
+	r.discard()
+	return r.advance()
+}
+
+// Reset replaces the source of r with src, wrapped again by any options, such
+// as WithChaCha20, that wrap the source.  All cached data is discarded and the
+// current page is immediately loaded from src.  Reset allows the source of a
+// CachedReader that is shared with other packages to be changed, such as when
+// migrating to a hardware security module.  If src returns an error then no
+// cached data is served until a subsequent Read is able to load a page.
+func (r *CachedReader) Reset(src io.Reader) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrClosed
+	}
+	if r.health != nil {
+		r.health.reset()
+	}
+	r.r = r.source(src)
+	r.dups.setSource(src)
+	r.discard()
+	return r.advance()
+}
+
+// discard zeros all the pages and requests that the DRBGs wrapping the source,
+// if any, reseed, so none of the discarded data can be generated again.  Reads
+// in progress will discard the data they copied and new Reads will call fill.
+// r.mu must be held.
+func (r *CachedReader) discard() {
+	if !r.closed {
+		r.log(slog.LevelInfo, "cachedrander: cached data discarded")
+	}
+	r.discards.Add(1)
+	r.dropPages()
+	clear(r.stuckPrev)
+	r.stuckPrev = r.stuckPrev[:0]
+	r.reseedDRBGs()
+	r.seedServed = r.served.Load()
+}
+
+// dropPages zeros all the pages and exhausts the current page, so the next
+// Read, or advance, loads the page following it.  A generation whose buffer
+// was zeroed is only loaded again if it never became the current page.  r.mu
+// must be held.
+func (r *CachedReader) dropPages() {
+	p := r.cur.Load()
+	for i, n := 1, r.standby(); i <= n; i++ {
+		r.addWasted(uint64(len(r.bufs[(p.gen+uint64(i))%uint64(len(r.bufs))].data)))
+	}
+	for _, b := range r.bufs {
+		b.invalidate()
+		clear(b.data)
+	}
+	for !r.exhaust(p) {
+		// A concurrent swap replaced p.
+		p = r.cur.Load()
+	}
+}
+
+// exhaust replaces the current page, p, with an exhausted copy so the next Read
+// advances to the next page and a concurrent swap from p fails.  The unserved
+// remainder of p is counted as wasted.  It reports false if p is no longer the
+// current page.  r.mu must be held.
+func (r *CachedReader) exhaust(p *page) bool {
+	exhausted := &page{buf: p.buf, data: p.data, gen: p.gen, size: p.size}
+	exhausted.offset.Store(p.size + 1)
+	exhausted.retired.Store(true)
+	if !r.cur.CompareAndSwap(p, exhausted) {
+		return false
+	}
+	if used, ok := r.retire(p); ok {
+		r.addWasted(p.size - used)
+	}
+	return true
+}
+
+// noGen is the stamp of a buffer that is being loaded or was discarded.
+const noGen = ^uint64(0)
+
+// A buffer holds a page's worth of data.  Buffers are reused as pages cycle
+// through the ring.
+type buffer struct {
+	data  []byte
+	stamp atomic.Uint64           // the generation of the data in the buffer
+	key   atomic.Pointer[pageKey] // set by WithEncryptedPages
+	// loaded is when the buffer was last loaded.  It is protected by
+	// CachedReader.mu.
+	loaded time.Time
+
+	// pins counts the copies in progress and the unreleased slices
+	// returned by Next.  It is written by every Read so it is kept on its
+	// own cache line, apart from stamp, which every Read must also read.
+	_    cpu.CacheLinePad
+	pins atomic.Int64
+	_    cpu.CacheLinePad
+}
+
+// invalidate stamps b as not holding any generation and waits for the copies
+// from b in progress to finish, and all slices of b returned by Next to be
+// released, after which b may be overwritten.
+func (b *buffer) invalidate() {
+	b.stamp.Store(noGen)
+	for b.pins.Load() != 0 {
+		runtime.Gosched()
+	}
+}
+
+// A page describes the current page.  The page of generation gen uses buffer
+// gen%len(bufs).  Other than offset a page is never modified once published.
+type page struct {
+	buf       *buffer
+	data      []byte // buf.data when the page was published
+	gen       uint64
+	size      uint64    // len(data)
+	watermark uint64    // offset that triggers a background fill
+	key       *pageKey  // the key data is encrypted with, if any
+	start     time.Time // when the page was published, if adaptive
+	blocked   uint64    // r.blocked when the page was published, if adaptive
+
+	// offset is written by every Read so it is kept on its own cache line,
+	// apart from the fields above that every Read must also read.
+	_       cpu.CacheLinePad
+	offset  atomic.Uint64 // bytes reserved from the page
+	_       cpu.CacheLinePad
+	retired atomic.Bool // the page has been included in served
+}
+
+// newPage returns a page for generation gen, which has been loaded into b.
+func (r *CachedReader) newPage(b *buffer, gen uint64) *page {
+	size := uint64(len(b.data))
+	p := &page{
+		buf:       b,
+		data:      b.data,
+		gen:       gen,
+		size:      size,
+		watermark: uint64(float64(size) * r.fillAt),
+		key:       b.key.Load(),
+	}
+	if r.adaptive != nil {
+		p.start = time.Now()
+		p.blocked = r.blocked.Load()
+	}
+	return p
+}
 
 // Read fills buf with cached data
 func (r *CachedReader) Read(buf []byte) (int, error) {
-	if len(buf) > r.Max {
-		buf = buf[:r.Max]
+	return r.ReadContext(context.Background(), buf)
+}
+
+// ReadContext is like Read but returns ctx.Err() if ctx is done before the next
+// page becomes available.  The abandoned page load continues in the background
+// and its page is available to subsequent Reads.
+func (r *CachedReader) ReadContext(ctx context.Context, buf []byte) (int, error) {
+	if r.fullReads {
+		return r.readAll(ctx, buf)
+	}
+	if m := r.maxLen(); len(buf) > m {
+		buf = buf[:m]
 	}
 	blen := uint64(len(buf))
+	if blen == 0 {
+		return 0, nil
+	}
+	if r.stride > 0 {
+		return r.readStride(ctx, buf)
+	}
+	if err := r.checkFork(); err != nil {
+		return 0, err
+	}
 	for {
-		ai := atomic.AddUint64(&r.index, blen)
-		page := int(ai >> indexBits)
-		i := ai & indexMask
-		if i-blen <= r.size {
-			return copy(buf, r.pages[page][i-blen:]), nil
+		p := r.cur.Load()
+		end := p.offset.Add(blen)
+		if start := end - blen; start < p.size {
+			n, ok := r.copyAt(buf, p, start)
+			if !ok {
+				// The page was reloaded out from under us.
+				r.skipped.Add(blen)
+				continue
+			}
+			r.checkWatermark(p, start, end)
+			return n, nil
 		}
-		if err := r.fill(); err != nil {
+		if err := r.waitContext(ctx, p); err != nil {
+			if r.fallBack(err, buf) {
+				return len(buf), nil
+			}
 			return 0, err
 		}
 	}
 }
 
-// fill fills in the cache page we are currently not reading from.
+// waitContext calls wait, returning early if ctx is done or the fill timeout
+// expires first.
+func (r *CachedReader) waitContext(ctx context.Context, p *page) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if r.fillTimeout > 0 {
+		return r.waitTimeout(ctx, p)
+	}
+	return r.wait(ctx, p)
+}
+
+// wait is called by a Read that found the current page, p, exhausted.  It
+// returns once the next page is available or ctx is done.
+func (r *CachedReader) wait(ctx context.Context, p *page) error {
+	if r.swap(p) {
+		return nil
+	}
+	r.blocked.Add(1)
+	start := time.Now()
+	err := r.await(ctx, p)
+	d := time.Since(start)
+	r.blockedHist.add(d)
+	if r.metrics != nil {
+		r.metrics.Blocked(d)
+	}
+	return err
+}
+
+// await waits for the page following p.  While another goroutine is loading a
+// page it waits for the load to finish, rather than for r.mu, and then tries to
+// swap in the next page.  Otherwise it loads the next page with fill, which is
+// run by runAsync if ctx can be canceled.
+func (r *CachedReader) await(ctx context.Context, p *page) error {
+	for {
+		waited, err := r.loads.wait(ctx)
+		if err != nil {
+			return err
+		}
+		if !waited {
+			break
+		}
+		if r.cur.Load() != p || r.swap(p) {
+			return nil
+		}
+	}
+	if ctx.Done() == nil {
+		return r.fill()
+	}
+	c := runAsync(&r.filling, r.fill)
+	select {
+	case <-c.done:
+		return c.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// swap replaces p with the next page, without acquiring r.mu, if the next
+// page has already been loaded by the background filler.  It reports false if
+// the caller must wait for the next page to be loaded.
+func (r *CachedReader) swap(p *page) bool {
+	if r.detectFork && forkState.word == nil {
+		// Forks are only detected by fill.
+		return false
+	}
+	gen := p.gen + 1
+	b := r.bufs[gen%uint64(len(r.bufs))]
+	if b.stamp.Load() != gen {
+		return false
+	}
+	if r.cur.CompareAndSwap(p, r.newPage(b, gen)) {
+		if _, ok := r.retire(p); ok {
+			r.adapt(p)
+		}
+		if r.refill != nil {
+			r.signal()
+		}
+	}
+	// Either we swapped or someone else replaced p first.
+	return true
+}
+
+// copyAt copies the data at offset i of p into buf.  It reports false, and the
+// copied data must be discarded, if p's buffer no longer holds p's generation.
+// This happens when the caller was delayed long enough for the buffer to be
+// reloaded, in which case the data may also have been returned to another
+// caller.
+func (r *CachedReader) copyAt(buf []byte, p *page, i uint64) (int, bool) {
+	// Pinning the buffer before checking its stamp keeps it from being
+	// reloaded, or freed, while the data is copied (see invalidate).
+	p.buf.pins.Add(1)
+	if p.buf.stamp.Load() != p.gen {
+		p.buf.pins.Add(-1)
+		return 0, false
+	}
+	n := copy(buf, p.data[i:])
+	if r.zeroize {
+		clear(p.data[i : i+uint64(n)])
+	}
+	p.buf.pins.Add(-1)
+	if p.key != nil {
+		p.key.decrypt(buf[:n], i)
+	}
+	r.dups.check(buf[:n])
+	r.record(buf[:n])
+	return n, true
+}
+
+// plainCopy reports whether Reads may copy directly from the pages, without
+// the extra work done by copyAt for options such as WithEncryptedPages.
+func (r *CachedReader) plainCopy() bool {
+	return !r.zeroize && !r.encrypt && r.recorder == nil
+}
+
+// checkWatermark signals the background filler if the range of p from start to
+// end crosses the watermark.
+func (r *CachedReader) checkWatermark(p *page, start, end uint64) {
+	if r.refill != nil && start <= p.watermark && end > p.watermark {
+		r.signal()
+	}
+}
+
+// signal wakes up the background filler without blocking.
+func (r *CachedReader) signal() {
+	select {
+	case r.refill <- struct{}{}:
+	default:
+	}
+}
+
+// filler loads the standby pages each time it is signaled until r is closed.
+func (r *CachedReader) filler() {
+	labelGoroutine("filler")
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-r.refill:
+		}
+		if r.predict == nil || r.warm {
+			for r.loadStandby() {
+			}
+			continue
+		}
+		if !r.predictiveWait() {
+			return
+		}
+		for {
+			start := time.Now()
+			if !r.loadStandby() {
+				break
+			}
+			r.predict.loaded(time.Since(start))
+		}
+	}
+}
+
+// loadStandby loads the next unloaded standby page.  It reports false if all
+// the standby pages are already loaded or the source returned an error.  On
+// error the next call to fill will report the error to its caller.
+func (r *CachedReader) loadStandby() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return false
+	}
+	n := r.standby()
+	if n >= len(r.bufs)-1 {
+		return false
+	}
+	count := 1
+	if r.workers > 1 {
+		count = len(r.bufs) - 1 - n
+	}
+	return r.loadPages(r.cur.Load().gen+uint64(n)+1, count) == nil
+}
+
+// standby returns the number of pages following the current page that have
+// already been loaded.  r.mu must be held.  Pages may be made current by swap
+// while r.mu is held but no page can become loaded.
+func (r *CachedReader) standby() int {
+	gen := r.cur.Load().gen
+	n := 0
+	for n < len(r.bufs)-1 {
+		g := gen + uint64(n) + 1
+		if r.bufs[g%uint64(len(r.bufs))].stamp.Load() != g {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// fill makes the next page in the ring the current page, loading it first if
+// the background filler has not already done so.  The current page is left in
+// place if the source returns an error.
 func (r *CachedReader) fill() error {
 	r.mu.Lock()
-	ai := atomic.LoadUint64(&r.index)
-	var err error
-	if (ai & indexMask) > r.size {
-		page := (ai >> indexBits) ^ 1
-		_, err = io.ReadFull(r.r, r.pages[page])
-		atomic.StoreUint64(&r.index, uint64(page)<<indexBits)
-	}
-	r.mu.Unlock()
-	return err
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrClosed
+	}
+	if r.detectFork {
+		if gen := checkPID(); gen != r.forkGen.Load() {
+			r.discard()
+			r.forkGen.Store(gen)
+		}
+	}
+	if p := r.cur.Load(); p.offset.Load() <= p.size {
+		// Someone else already filled it.
+		return nil
+	}
+	return r.advance()
+}
+
+// advance makes the next page in the ring the current page.  r.mu must be
+// held.
+func (r *CachedReader) advance() error {
+	p := r.cur.Load()
+	gen := p.gen + 1
+	b := r.bufs[gen%uint64(len(r.bufs))]
+	if b.stamp.Load() != gen {
+		if err := r.load(gen); err != nil {
+			return err
+		}
+	}
+	// A concurrent swap may have already replaced p.
+	if r.cur.CompareAndSwap(p, r.newPage(b, gen)) {
+		if _, ok := r.retire(p); ok {
+			r.adapt(p)
+		}
+	}
+	if r.refill != nil {
+		r.signal()
+	}
+	return nil
+}
+
+// load reads a page's worth of data from the source into the buffer used by
+// generation gen.  The buffer is stamped as invalid while it is being loaded so
+// readers of its previous generation will discard what they copied.  A
+// FillError is returned if the source fails.
+func (r *CachedReader) load(gen uint64) error {
+	b, err := r.prepare(gen)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	n, err := r.readPage(b.data)
+	return r.finish(gen, b, n, err, start)
+}
+
+// prepare returns the buffer for generation gen, invalidated and sized to be
+// loaded.  r.mu must be held.
+func (r *CachedReader) prepare(gen uint64) (*buffer, error) {
+	if !r.breaker.allow(time.Now()) {
+		return nil, ErrCircuitOpen
+	}
+	b := r.bufs[gen%uint64(len(r.bufs))]
+	b.invalidate()
+	if size := r.size.Load(); uint64(len(b.data)) != size {
+		// The size was changed by Resize.
+		data, err := r.allocate(int(size))
+		if err != nil {
+			return nil, err
+		}
+		r.free(b.data)
+		b.data = data
+	}
+	r.checkReseed()
+	r.loads.start()
+	return b, nil
+}
+
+// finish completes the load of generation gen into b, started at start, for
+// which readPage returned n and err.  It checks the data, records the load,
+// and stamps b if the load succeeded.  r.mu must be held.
+func (r *CachedReader) finish(gen uint64, b *buffer, n int, err error, start time.Time) error {
+	defer r.loads.finish()
+	if err == nil && r.sanity {
+		err = checkSanity(b.data)
+	}
+	if err == nil && r.stuck {
+		err = r.checkStuck(gen, b.data)
+	}
+	d := time.Since(start)
+	if err == nil && r.preformed {
+		preform(b.data)
+	}
+	if err == nil && r.encrypt {
+		err = encryptPage(b)
+	}
+	var ferr error
+	if err != nil {
+		ferr = &FillError{Page: gen, Offset: n, Err: err}
+	}
+	r.statMu.Lock()
+	wasOpen := r.breaker.open()
+	r.breaker.record(err, start.Add(d))
+	open := r.breaker.open()
+	r.fills++
+	r.fillTime += d
+	r.fillHist.add(d)
+	r.lastErr = ferr
+	r.statMu.Unlock()
+
+	r.logFill(gen, d, err)
+	r.checkSlowFill(gen, b, d, err)
+	if open != wasOpen {
+		if open {
+			r.log(slog.LevelWarn, "cachedrander: circuit breaker opened", "error", err)
+		} else {
+			r.log(slog.LevelWarn, "cachedrander: circuit breaker closed")
+		}
+	}
+	if r.metrics != nil {
+		r.metrics.Fill(d, err)
+	}
+	if ferr != nil {
+		return ferr
+	}
+	b.loaded = time.Now()
+	b.stamp.Store(gen)
+	return nil
 }
@@ -1,10 +1,15 @@
 package cachedrander
 
 import (
+	"context"
+	"errors"
 	"io"
 	"testing"
+	"time"
+	"unsafe"
 
 	"github.com/google/uuid"
+	"golang.org/x/sys/cpu"
 )
 
 type gen struct {
@@ -38,6 +43,80 @@ func TestReader(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	checkSequential(t, r)
+}
+
+func TestBackgroundFill(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 1024, WithBackgroundFill(0.75))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	r.Max = 8
+	checkSequential(t, r)
+}
+
+func TestSwap(t *testing.T) {
+	g := &gen{size: 64}
+	r, err := New(g, 64, WithBackgroundFill(0.5), WithMax(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var buf [16]byte
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 4; j++ {
+			if _, err := r.Read(buf[:]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// Wait for the filler to load the next page.
+		deadline := time.Now().Add(time.Second)
+		for r.Stats().Fills < uint64(i+2) {
+			if time.Now().After(deadline) {
+				t.Fatalf("page %d: standby page not loaded", i+1)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	if s := r.Stats(); s.BlockedReads != 0 {
+		t.Errorf("got %d blocked reads, want 0", s.BlockedReads)
+	}
+}
+
+func TestWarmStandby(t *testing.T) {
+	for _, tt := range []struct {
+		opts  []Option
+		pages int
+	}{
+		{nil, 3},
+		{[]Option{WithPageCount(2)}, 3},
+		{[]Option{WithPageCount(5)}, 5},
+	} {
+		g := &gen{size: 17}
+		r, err := New(g, 64, append(tt.opts, WithWarmStandby(), WithMax(8))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(r.bufs) != tt.pages {
+			t.Errorf("got %d pages, want %d", len(r.bufs), tt.pages)
+		}
+		if s := r.Stats(); s.Fills != uint64(tt.pages) {
+			t.Errorf("got %d fills, want %d", s.Fills, tt.pages)
+		}
+		checkSequential(t, r)
+		r.Close()
+	}
+}
+
+// checkSequential reads from r, which must be reading from a gen, and verifies
+// that the bytes are returned in order.
+func checkSequential(t *testing.T, r io.Reader) {
+	t.Helper()
 	var buf [64]byte
 
 	next := 0
@@ -80,3 +159,292 @@ func BenchmarkCached(b *testing.B) {
 		}
 	}
 }
+
+func TestReloadedPage(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [16]byte
+
+	// Reserve the first 16 bytes of the first page, as Read does, but
+	// do not copy them until both pages have been reloaded.
+	p := r.cur.Load()
+	p.offset.Add(16)
+	if _, ok := r.copyAt(buf[:], p, 0); !ok {
+		t.Fatal("copy from current page failed")
+	}
+	for i := 0; i < 8; i++ {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := r.copyAt(buf[:], p, 0); ok {
+		t.Fatal("copy from reloaded page succeeded")
+	}
+}
+
+func TestPageCount(t *testing.T) {
+	for _, opts := range [][]Option{
+		{WithPageCount(3)},
+		{WithPageCount(4), WithBackgroundFill(0.5)},
+	} {
+		g := &gen{size: 17}
+		r, err := New(g, 256, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Max = 8
+		checkSequential(t, r)
+		r.Close()
+	}
+}
+
+func TestClose(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64, WithBackgroundFill(0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [16]byte
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for i, buf := range r.bufs {
+		for _, b := range buf.data {
+			if b != 0 {
+				t.Fatalf("page %d was not zeroed", i)
+			}
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := r.Read(buf[:]); err != ErrClosed {
+			t.Fatalf("Read after Close got %v, want %v", err, ErrClosed)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
+
+func TestReseed(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64, WithPageCount(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [16]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	p := r.cur.Load()
+	next := g.head
+	if err := r.Reseed(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.copyAt(buf[:], p, 0); ok {
+		t.Fatal("copy from discarded page succeeded")
+	}
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	if buf[0] != byte(next) {
+		t.Fatalf("got byte %d, want %d", buf[0], byte(next))
+	}
+}
+
+func TestReset(t *testing.T) {
+	f := &flakyReader{}
+	r, err := New(f, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [16]byte
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reset(&gen{size: 17}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	if buf[0] != 0 || buf[15] != 15 {
+		t.Errorf("got %v, want data from the new source", buf)
+	}
+	if s := r.Stats(); s.WastedBytes != 48 {
+		t.Errorf("WastedBytes got %d, want 48", s.WastedBytes)
+	}
+
+	// Options that wrap the source wrap the new source too.
+	r, err = New(f, 64, WithSHA256Conditioning(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.fail = true
+	if err := r.Reset(&gen{size: 17}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.r.(*conditioner); !ok {
+		t.Errorf("got source %T, want *conditioner", r.r)
+	}
+	r.Close()
+	if err := r.Reset(f); err != ErrClosed {
+		t.Errorf("got error %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestResize(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Resize(100); err != nil {
+		t.Fatal(err)
+	}
+	// The current page keeps its size, following pages have the new size
+	// rounded up to a multiple of Max.  No data is skipped.
+	var buf [16]byte
+	for i := 0; i < 2+7+1; i++ {
+		if _, err := r.Read(buf[:]); err != nil {
+			t.Fatal(err)
+		}
+		if buf[0] != byte(i*16) {
+			t.Fatalf("read %d: got data starting at %d, want %d", i, buf[0], byte(i*16))
+		}
+		want := uint64(112)
+		if i < 2 {
+			want = 32
+		}
+		if got := r.cur.Load().size; got != want {
+			t.Errorf("read %d: got page size %d, want %d", i, got, want)
+		}
+	}
+	if err := r.Resize(8); !errors.Is(err, ErrInvalidSize) {
+		t.Errorf("got error %v, want %v", err, ErrInvalidSize)
+	}
+}
+
+// A slowReader blocks each Read until release is closed.
+type slowReader struct {
+	release chan struct{}
+}
+
+func (s *slowReader) Read(buf []byte) (int, error) {
+	<-s.release
+	return len(buf), nil
+}
+
+func TestReadContext(t *testing.T) {
+	s := &slowReader{release: make(chan struct{})}
+	close(s.release)
+	r, err := New(s, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.release = make(chan struct{})
+	var buf [16]byte
+	for i := 0; i < 2; i++ {
+		if _, err := r.ReadContext(context.Background(), buf[:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := r.ReadContext(ctx, buf[:]); err != context.DeadlineExceeded {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	close(s.release)
+	if _, err := r.ReadContext(context.Background(), buf[:]); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInvalidSize(t *testing.T) {
+	for _, tt := range []struct {
+		size int
+		opts []Option
+	}{
+		{size: 0},
+		{size: -16},
+		{size: 8},
+		{size: 64, opts: []Option{WithMax(128)}},
+		{size: 64, opts: []Option{WithMax(0)}},
+		{size: 64, opts: []Option{WithMax(-1)}},
+	} {
+		if _, err := New(&gen{size: 17}, tt.size, tt.opts...); !errors.Is(err, ErrInvalidSize) {
+			t.Errorf("New(%d) got error %v, want %v", tt.size, err, ErrInvalidSize)
+		}
+	}
+
+	r, err := New(&gen{size: 17}, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.size.Load() != 48 {
+		t.Errorf("got size %d, want 48", r.size.Load())
+	}
+}
+
+func TestSetMaxRead(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [64]byte
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			r.Read(buf[:16])
+		}
+	}()
+	if err := r.SetMaxRead(32); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if n, err := r.Read(buf[:]); err != nil || n > 32 {
+		t.Errorf("got %d bytes, %v, want at most 32", n, err)
+	}
+	for _, n := range []int{0, 65} {
+		if err := r.SetMaxRead(n); !errors.Is(err, ErrInvalidSize) {
+			t.Errorf("SetMaxRead(%d) got error %v, want %v", n, err, ErrInvalidSize)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	line := unsafe.Sizeof(cpu.CacheLinePad{})
+	var p page
+	if d := unsafe.Offsetof(p.offset) - unsafe.Offsetof(p.blocked); d < line {
+		t.Errorf("page offset is %d bytes from the fields read with it, want at least %d", d, line)
+	}
+	var b buffer
+	if d := unsafe.Offsetof(b.pins) - unsafe.Offsetof(b.stamp); d < line {
+		t.Errorf("buffer pins is %d bytes from stamp, want at least %d", d, line)
+	}
+	if d := unsafe.Sizeof(b) - unsafe.Offsetof(b.pins); d < line {
+		t.Errorf("buffer pins is %d bytes from the end of the buffer, want at least %d", d, line)
+	}
+	var r CachedReader
+	for _, f := range []struct {
+		name   string
+		offset uintptr
+	}{
+		{"mu", unsafe.Offsetof(r.mu)},
+		{"served", unsafe.Offsetof(r.served)},
+	} {
+		if d := f.offset - unsafe.Offsetof(r.err); d < line {
+			t.Errorf("%s is %d bytes from the fields read by Read, want at least %d", f.name, d, line)
+		}
+	}
+	if d := unsafe.Offsetof(r.served) - unsafe.Offsetof(r.lastErr); d < line {
+		t.Errorf("counters are %d bytes from the fields protected by mu, want at least %d", d, line)
+	}
+}
@@ -0,0 +1,101 @@
+package cachedrander
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// ErrSourceTooSlow is returned, wrapped with details, by Calibrate when the
+// source cannot deliver data as fast as it would be consumed.
+var ErrSourceTooSlow = errors.New("cachedrander: source is too slow")
+
+const (
+	// calibrateTime is how long Calibrate reads from the source.
+	calibrateTime = 100 * time.Millisecond
+
+	// targetFills is the number of pages Calibrate aims to have loaded
+	// per second.
+	targetFills = 10
+)
+
+// A Calibration is the result of Calibrate.
+type Calibration struct {
+	// SourceRate is the measured rate, in bytes per second, at which the
+	// source delivers data.
+	SourceRate float64
+
+	// PageSize and PageCount are the suggested size of each page and number
+	// of pages.
+	PageSize  int
+	PageCount int
+}
+
+// Options returns the Options, to be passed to New along with c.PageSize, that
+// configure a CachedReader as suggested by c.
+func (c Calibration) Options() []Option {
+	return []Option{WithPageCount(c.PageCount), WithBackgroundFill(0.5)}
+}
+
+// Calibrate benchmarks the source of r and suggests a page size and page count
+// for serving targetQPS Reads of Max bytes per second.  Pages are sized so they
+// are loaded about 10 times a second, and there are enough of them for a
+// background filler (see WithBackgroundFill) to keep up.  The source is read for
+// about 100ms, or until ctx is done, and the data read is discarded.
+//
+// Calibrate only suggests a configuration: r.Resize(c.PageSize) applies the page
+// size to r, while the page count requires creating a new CachedReader with
+// c.Options().  If the source cannot keep up with targetQPS, the suggestion is
+// returned along with an error wrapping ErrSourceTooSlow.
+func (r *CachedReader) Calibrate(ctx context.Context, targetQPS float64) (Calibration, error) {
+	if targetQPS <= 0 || math.IsInf(targetQPS, 0) || math.IsNaN(targetQPS) {
+		return Calibration{}, fmt.Errorf("cachedrander: invalid target QPS %v", targetQPS)
+	}
+	rate, err := r.benchmark(ctx)
+	if err != nil {
+		return Calibration{}, err
+	}
+	c := Calibration{SourceRate: rate}
+
+	demand := targetQPS * float64(r.maxLen())
+	size := int(math.Min(math.Ceil(demand/targetFills), math.MaxInt32))
+	if c.PageSize, err = r.checkSize(max(size, r.maxLen())); err != nil {
+		return Calibration{}, err
+	}
+	// While one page is loaded the others must serve the demand, so n-1
+	// pages must last as long as loading a page takes.
+	c.PageCount = 2
+	if rate > 0 {
+		c.PageCount = max(c.PageCount, 2+int(math.Ceil(demand/rate)))
+	}
+	if demand >= rate {
+		return c, fmt.Errorf("%w: %.0f bytes/s needed, %.0f bytes/s available", ErrSourceTooSlow, demand, rate)
+	}
+	return c, nil
+}
+
+// benchmark reads from the source of r for calibrateTime, or until ctx is
+// done, and returns the rate in bytes per second.
+func (r *CachedReader) benchmark(ctx context.Context) (float64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return 0, ErrClosed
+	}
+	buf := make([]byte, r.size.Load())
+	var n int
+	start := time.Now()
+	for n == 0 || (time.Since(start) < calibrateTime && ctx.Err() == nil) {
+		m, err := io.ReadFull(r.r, buf)
+		n += m
+		r.addWasted(uint64(m))
+		if err != nil {
+			return 0, err
+		}
+	}
+	clear(buf)
+	return float64(n) / time.Since(start).Seconds(), nil
+}
@@ -0,0 +1,85 @@
+package cachedrander
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// sleepyReader returns zeros, sleeping for 10ms on each Read.
+type sleepyReader struct{}
+
+func (sleepyReader) Read(buf []byte) (int, error) {
+	time.Sleep(10 * time.Millisecond)
+	clear(buf)
+	return len(buf), nil
+}
+
+func TestCalibrate(t *testing.T) {
+	r, err := New(&gen{size: 256}, 1600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := r.Calibrate(context.Background(), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.SourceRate < 1e6 {
+		t.Errorf("got rate %v, want at least 1e6", c.SourceRate)
+	}
+	if c.PageSize != 1600 {
+		t.Errorf("got page size %d, want 1600", c.PageSize)
+	}
+	if c.PageCount != 3 {
+		t.Errorf("got page count %d, want 3", c.PageCount)
+	}
+	if w := r.Stats().WastedBytes; w == 0 {
+		t.Error("calibration data was not counted as wasted")
+	}
+	if err := r.Resize(c.PageSize); err != nil {
+		t.Fatal(err)
+	}
+	nr, err := New(&gen{size: 256}, c.PageSize, c.Options()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nr.bufs) != c.PageCount {
+		t.Errorf("got %d pages, want %d", len(nr.bufs), c.PageCount)
+	}
+	nr.Close()
+
+	// 1600 bytes every 10ms cannot serve 1M UUIDs a second.
+	if err := r.Reset(sleepyReader{}); err != nil {
+		t.Fatal(err)
+	}
+	c, err = r.Calibrate(context.Background(), 1<<20)
+	if !errors.Is(err, ErrSourceTooSlow) {
+		t.Errorf("got error %v, want %v", err, ErrSourceTooSlow)
+	}
+	if want := 1677728; c.PageSize != want {
+		t.Errorf("got page size %d, want %d", c.PageSize, want)
+	}
+	if c.SourceRate > 160000 || c.PageCount < 100 {
+		t.Errorf("got rate %v and %d pages", c.SourceRate, c.PageCount)
+	}
+
+	// Calibrate stops reading when ctx is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if _, err := r.Calibrate(ctx, 1000); err != nil && !errors.Is(err, ErrSourceTooSlow) {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d >= calibrateTime {
+		t.Errorf("canceled Calibrate took %v", d)
+	}
+
+	if _, err := r.Calibrate(context.Background(), 0); err == nil {
+		t.Error("zero QPS did not return an error")
+	}
+	r.Close()
+	if _, err := r.Calibrate(context.Background(), 1000); err != ErrClosed {
+		t.Errorf("got error %v, want %v", err, ErrClosed)
+	}
+}
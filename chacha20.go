@@ -0,0 +1,76 @@
+package cachedrander
+
+import (
+	"io"
+
+	"golang.org/x/crypto/chacha20"
+)
+
+// maxChaCha20Interval is the most data that can be generated with a single
+// ChaCha20 key and nonce before its 32 bit block counter overflows.
+const maxChaCha20Interval = 1 << 38
+
+// WithChaCha20 causes the CachedReader to read a 32 byte key from its source
+// and then fill its pages with the ChaCha20 keystream for that key rather than
+// reading them from the source.  A new key is read from the source after every
+// interval bytes of keystream.  An interval of 0, or one larger than ChaCha20
+// supports (256GB), is treated as the maximum.  This greatly reduces the number
+// of reads from the source, such as crypto/rand, while retaining cryptographic
+// quality output.
+func WithChaCha20(interval uint64) Option {
+	return func(r *CachedReader) {
+		if interval == 0 || interval > maxChaCha20Interval {
+			interval = maxChaCha20Interval
+		}
+		r.wrap(func(src io.Reader) io.Reader {
+			return &chacha20Reader{src: src, interval: interval}
+		})
+	}
+}
+
+// A chacha20Reader generates the ChaCha20 keystream for a key read from src.
+type chacha20Reader struct {
+	src      io.Reader
+	interval uint64
+	n        uint64 // bytes generated with the current key
+	c        *chacha20.Cipher
+}
+
+// Read fills buf with keystream, reseeding first if needed.  Read returns a
+// short read when the reseed interval is reached.
+func (c *chacha20Reader) Read(buf []byte) (int, error) {
+	if c.c == nil || c.n >= c.interval {
+		if err := c.reseed(); err != nil {
+			return 0, err
+		}
+	}
+	if left := c.interval - c.n; uint64(len(buf)) > left {
+		buf = buf[:left]
+	}
+	clear(buf)
+	c.c.XORKeyStream(buf, buf)
+	c.n += uint64(len(buf))
+	return len(buf), nil
+}
+
+// requestReseed causes a new key to be read by the next Read.
+func (c *chacha20Reader) requestReseed() {
+	c.c = nil
+}
+
+// reseed reads a new key from src.
+func (c *chacha20Reader) reseed() error {
+	var key [chacha20.KeySize]byte
+	if _, err := io.ReadFull(c.src, key[:]); err != nil {
+		return err
+	}
+	var nonce [chacha20.NonceSize]byte
+	cipher, err := chacha20.NewUnauthenticatedCipher(key[:], nonce[:])
+	clear(key[:])
+	if err != nil {
+		return err
+	}
+	c.c = cipher
+	c.n = 0
+	return nil
+}
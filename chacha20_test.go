@@ -0,0 +1,51 @@
+package cachedrander
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestChaCha20(t *testing.T) {
+	g := &gen{size: 32}
+	r, err := New(g, 256, WithChaCha20(512))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.head != 32 {
+		t.Fatalf("initial fill read %d bytes from the source, want 32", g.head)
+	}
+	var first [16]byte
+	if _, err := io.ReadFull(r, first[:]); err != nil {
+		t.Fatal(err)
+	}
+	if first == [16]byte{} {
+		t.Fatal("got all zero keystream")
+	}
+	var buf [16]byte
+	for i := 0; i < 64; i++ {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 65 reads of 16 bytes uses 5 pages, or 1280 bytes, requiring 3 keys.
+	if g.head != 3*32 {
+		t.Errorf("read %d bytes from the source, want %d", g.head, 3*32)
+	}
+
+	// The same key must produce the same keystream.
+	r2, err := New(bytes.NewReader(make([]byte, 32)), 16, WithChaCha20(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r3, err := New(bytes.NewReader(make([]byte, 32)), 16, WithChaCha20(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b2, b3 [16]byte
+	io.ReadFull(r2, b2[:])
+	io.ReadFull(r3, b3[:])
+	if b2 != b3 {
+		t.Errorf("same key produced %x and %x", b2, b3)
+	}
+}
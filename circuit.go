@@ -0,0 +1,63 @@
+package cachedrander
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrCircuitOpen is returned when WithCircuitBreaker is used and the source is
+// not being read because it failed repeatedly.
+var ErrCircuitOpen = errors.New("cachedrander: source circuit breaker is open")
+
+// A breaker stops reads from a failing source.  It is written holding both the
+// CachedReader's mu and statMu, so it may be read holding either.
+type breaker struct {
+	threshold  int           // consecutive failures that open the circuit
+	probeAfter time.Duration // how long the circuit stays open
+	failures   int           // consecutive failures
+	openedAt   time.Time     // zero if the circuit is closed
+	trips      uint64        // number of times the circuit opened
+}
+
+// WithCircuitBreaker stops loading pages from the source after it fails
+// threshold times in a row, such as when a hardware device is removed.  While
+// the circuit is open page loads fail immediately with ErrCircuitOpen and reads
+// are served directly from crypto/rand, as with WithFallback.  After probeAfter
+// has passed the next page load probes the source, closing the circuit if it
+// succeeds and reopening it if it fails.  The state of the circuit is reported
+// by Stats.  Values of threshold less than 1 are treated as 1.
+func WithCircuitBreaker(threshold int, probeAfter time.Duration) Option {
+	return func(r *CachedReader) {
+		r.breaker = &breaker{threshold: max(threshold, 1), probeAfter: probeAfter}
+		r.fallback = true
+	}
+}
+
+// allow reports whether the source may be read at time now.  A nil breaker
+// always allows reads.
+func (b *breaker) allow(now time.Time) bool {
+	return b == nil || b.openedAt.IsZero() || now.Sub(b.openedAt) >= b.probeAfter
+}
+
+// record records the result of reading the source at time now.
+func (b *breaker) record(err error, now time.Time) {
+	switch {
+	case b == nil:
+	case err == nil:
+		b.failures = 0
+		b.openedAt = time.Time{}
+	case !b.openedAt.IsZero():
+		// A failed probe.
+		b.openedAt = now
+	default:
+		if b.failures++; b.failures >= b.threshold {
+			b.openedAt = now
+			b.trips++
+		}
+	}
+}
+
+// open reports whether the circuit is open.
+func (b *breaker) open() bool {
+	return b != nil && !b.openedAt.IsZero()
+}
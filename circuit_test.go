@@ -0,0 +1,53 @@
+package cachedrander
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	f := &flakyReader{}
+	r, err := New(f, 16, WithCircuitBreaker(2, 20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.fail = true
+	f.reads = 0
+	var buf [16]byte
+	for i := 0; i < 5; i++ {
+		if _, err := r.Read(buf[:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first page was served, then two failures opened the circuit.
+	if f.reads != 2 {
+		t.Errorf("got %d reads of the source, want 2", f.reads)
+	}
+	s := r.Stats()
+	if !s.CircuitOpen || s.CircuitTrips != 1 {
+		t.Errorf("got CircuitOpen %v, CircuitTrips %d, want true, 1", s.CircuitOpen, s.CircuitTrips)
+	}
+	if s.Fallbacks != 4 {
+		t.Errorf("got %d fallbacks, want 4", s.Fallbacks)
+	}
+
+	// A failed probe keeps the circuit open.
+	time.Sleep(20 * time.Millisecond)
+	r.Read(buf[:])
+	if f.reads != 3 || !r.Stats().CircuitOpen {
+		t.Fatalf("failed probe: got %d reads, circuit open %v", f.reads, r.Stats().CircuitOpen)
+	}
+
+	// A successful probe closes it.
+	time.Sleep(20 * time.Millisecond)
+	f.fail = false
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	if buf[0] != 1 {
+		t.Errorf("got %v, want data from the source", buf)
+	}
+	if s := r.Stats(); s.CircuitOpen || s.CircuitTrips != 1 {
+		t.Errorf("got CircuitOpen %v, CircuitTrips %d, want false, 1", s.CircuitOpen, s.CircuitTrips)
+	}
+}
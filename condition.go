@@ -0,0 +1,43 @@
+package cachedrander
+
+import (
+	"crypto/sha256"
+	"io"
+)
+
+// WithSHA256Conditioning causes the data read from the source to be passed
+// through a SHA-256 based extractor before it is cached.  Each 32 bytes of
+// output is the SHA-256 hash of ratio*32 bytes read from the source, so a
+// source whose output is biased, such as a hardware random number generator
+// or jitter collector, yields full entropy output as long as it provides at
+// least 8/ratio bits of entropy per byte.  A ratio less than 1 is treated as 2.
+func WithSHA256Conditioning(ratio int) Option {
+	return func(r *CachedReader) {
+		if ratio < 1 {
+			ratio = 2
+		}
+		r.wrap(func(src io.Reader) io.Reader {
+			return &conditioner{r: src, raw: make([]byte, ratio*sha256.Size)}
+		})
+	}
+}
+
+// A conditioner hashes the data read from r.
+type conditioner struct {
+	r   io.Reader
+	raw []byte
+}
+
+// Read fills buf with conditioned data.
+func (c *conditioner) Read(buf []byte) (int, error) {
+	defer clear(c.raw)
+	n := 0
+	for n < len(buf) {
+		if _, err := io.ReadFull(c.r, c.raw); err != nil {
+			return n, err
+		}
+		sum := sha256.Sum256(c.raw)
+		n += copy(buf[n:], sum[:])
+	}
+	return n, nil
+}
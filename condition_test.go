@@ -0,0 +1,24 @@
+package cachedrander
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"testing"
+)
+
+func TestSHA256Conditioning(t *testing.T) {
+	raw := bytes.Repeat([]byte{1}, 1024)
+	r, err := New(bytes.NewReader(raw), 64, WithSHA256Conditioning(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [16]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(raw[:128])
+	if !bytes.Equal(buf[:], sum[:16]) {
+		t.Errorf("got %x, want %x", buf, sum[:16])
+	}
+}
@@ -0,0 +1,133 @@
+package cachedrander
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"io"
+)
+
+// CTR_DRBG parameters for AES-256 from NIST SP 800-90A Rev. 1, table 3.
+const (
+	drbgKeyLen      = 32
+	drbgBlockLen    = aes.BlockSize
+	drbgSeedLen     = drbgKeyLen + drbgBlockLen
+	drbgMaxRequest  = 1 << 16 // 2^19 bits
+	drbgMaxInterval = 1 << 48
+)
+
+// ErrPersonalization is returned by New when the personalization string passed
+// to WithCTRDRBG is too long.
+var ErrPersonalization = errors.New("cachedrander: CTR_DRBG personalization string longer than 48 bytes")
+
+// WithCTRDRBG causes the CachedReader to fill its pages using the CTR_DRBG
+// construction of NIST SP 800-90A with AES-256 and no derivation function.  The
+// DRBG is instantiated with 48 bytes of entropy input read from the source and
+// the personalization string, which may be nil and must be no longer than 48
+// bytes.  The DRBG is reseeded with new entropy input from the source after
+// every interval generate requests.  Each generate request produces at most
+// 64KB.  An interval of 0, or one larger than 2^48, is treated as 2^48, the
+// maximum permitted by SP 800-90A.  The source must provide full entropy.
+func WithCTRDRBG(personalization []byte, interval uint64) Option {
+	return func(r *CachedReader) {
+		if len(personalization) > drbgSeedLen {
+			r.err = ErrPersonalization
+			return
+		}
+		if interval == 0 || interval > drbgMaxInterval {
+			interval = drbgMaxInterval
+		}
+		r.wrap(func(src io.Reader) io.Reader {
+			d := &ctrDRBG{src: src, interval: interval}
+			copy(d.pers[:], personalization)
+			return d
+		})
+	}
+}
+
+// A ctrDRBG is a CTR_DRBG whose entropy input is read from src.
+type ctrDRBG struct {
+	src      io.Reader
+	interval uint64
+	pers     [drbgSeedLen]byte // zero padded personalization string
+	counter  uint64            // the reseed_counter; 0 before instantiation
+	block    cipher.Block
+	v        [drbgBlockLen]byte
+}
+
+// Read fills buf with the output of a single generate request, instantiating
+// or reseeding the DRBG first as needed.  Requests for more than 64KB return a
+// short read.
+func (d *ctrDRBG) Read(buf []byte) (int, error) {
+	if d.counter == 0 || d.counter > d.interval {
+		if err := d.reseed(); err != nil {
+			return 0, err
+		}
+	}
+	if len(buf) > drbgMaxRequest {
+		buf = buf[:drbgMaxRequest]
+	}
+	for i := 0; i < len(buf); i += drbgBlockLen {
+		d.increment()
+		var out [drbgBlockLen]byte
+		d.block.Encrypt(out[:], d.v[:])
+		copy(buf[i:], out[:])
+	}
+	var zero [drbgSeedLen]byte
+	d.update(&zero)
+	d.counter++
+	return len(buf), nil
+}
+
+// requestReseed causes the next Read to reseed the DRBG.
+func (d *ctrDRBG) requestReseed() {
+	if d.counter != 0 {
+		d.counter = d.interval + 1
+	}
+}
+
+// reseed instantiates the DRBG on its first call and reseeds it thereafter.
+// The personalization string is only used when instantiating.
+func (d *ctrDRBG) reseed() error {
+	var seed [drbgSeedLen]byte
+	if _, err := io.ReadFull(d.src, seed[:]); err != nil {
+		return err
+	}
+	if d.counter == 0 {
+		for i := range seed {
+			seed[i] ^= d.pers[i]
+		}
+		var key [drbgKeyLen]byte
+		d.block, _ = aes.NewCipher(key[:])
+		d.v = [drbgBlockLen]byte{}
+	}
+	d.update(&seed)
+	clear(seed[:])
+	d.counter = 1
+	return nil
+}
+
+// update is the CTR_DRBG_Update function.
+func (d *ctrDRBG) update(data *[drbgSeedLen]byte) {
+	var temp [drbgSeedLen]byte
+	for i := 0; i < drbgSeedLen; i += drbgBlockLen {
+		d.increment()
+		d.block.Encrypt(temp[i:], d.v[:])
+	}
+	for i := range temp {
+		temp[i] ^= data[i]
+	}
+	d.block, _ = aes.NewCipher(temp[:drbgKeyLen])
+	copy(d.v[:], temp[drbgKeyLen:])
+	clear(temp[:])
+}
+
+// increment increments V as a 128 bit big endian counter.
+func (d *ctrDRBG) increment() {
+	for i := len(d.v) - 1; i >= 0; i-- {
+		d.v[i]++
+		if d.v[i] != 0 {
+			return
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package cachedrander
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestCTRDRBG(t *testing.T) {
+	read := func(pers []byte, n int) []byte {
+		r, err := New(bytes.NewReader(make([]byte, 48)), 64, WithCTRDRBG(pers, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf
+	}
+	a, b := read(nil, 16), read(nil, 16)
+	if !bytes.Equal(a, b) {
+		t.Errorf("same seed produced %x and %x", a, b)
+	}
+	if bytes.Equal(a, make([]byte, 16)) {
+		t.Error("got all zero output")
+	}
+	if c := read([]byte("cachedrander"), 16); bytes.Equal(a, c) {
+		t.Error("personalization string did not change the output")
+	}
+
+	if _, err := New(&gen{size: 48}, 64, WithCTRDRBG(make([]byte, 49), 0)); err != ErrPersonalization {
+		t.Errorf("got error %v, want %v", err, ErrPersonalization)
+	}
+}
+
+func TestCTRDRBGReseed(t *testing.T) {
+	g := &gen{size: 48}
+	r, err := New(g, 64, WithCTRDRBG(nil, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [16]byte
+	for i := 0; i < 20; i++ {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 20 reads of 16 bytes uses 5 pages, each a single generate request,
+	// and the DRBG is reseeded every 2 requests.
+	if g.head != 3*48 {
+		t.Errorf("read %d bytes from the source, want %d", g.head, 3*48)
+	}
+}
@@ -0,0 +1,53 @@
+package cachedrander
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultUUIDs is the number of UUIDs' worth of data cached per page by the
+// reader returned by Default.
+const DefaultUUIDs = 1000
+
+var defaultReader struct {
+	once sync.Once
+	r    *CachedReader
+	err  error
+}
+
+// initDefault returns the reader returned by Default, creating it on first
+// use.
+func initDefault() (*CachedReader, error) {
+	defaultReader.once.Do(func() {
+		defaultReader.r, defaultReader.err = NewUUIDReader(DefaultUUIDs,
+			WithBackgroundFill(0.75), WithForkDetection())
+	})
+	return defaultReader.r, defaultReader.err
+}
+
+// Default returns a shared CachedReader that caches DefaultUUIDs UUIDs' worth of
+// data from crypto/rand.Reader, loading pages in the background and reseeding
+// after a fork.  It is created the first time Default or Read is called.
+// Default panics if the first page cannot be read from crypto/rand.Reader.
+// The shared reader must not be closed.
+func Default() *CachedReader {
+	r, err := initDefault()
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Read fills buf with data from the shared CachedReader returned by Default.
+// Like crypto/rand.Read, Read always fills all of buf unless an error is
+// returned.
+func Read(buf []byte) (int, error) {
+	r, err := initDefault()
+	if err != nil {
+		return 0, err
+	}
+	if err := r.readChunks(context.Background(), buf); err != nil {
+		return 0, err
+	}
+	return len(buf), nil
+}
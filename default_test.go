@@ -0,0 +1,25 @@
+package cachedrander
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDefault(t *testing.T) {
+	r := Default()
+	if r != Default() {
+		t.Fatal("Default returned different readers")
+	}
+	// Reads may be larger than both Max and a page.
+	buf := make([]byte, DefaultUUIDs*16+100)
+	n, err := Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(buf) {
+		t.Errorf("got %d bytes, want %d", n, len(buf))
+	}
+	if bytes.Equal(buf[:16], make([]byte, 16)) {
+		t.Error("Read returned zeros")
+	}
+}
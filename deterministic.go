@@ -0,0 +1,40 @@
+package cachedrander
+
+import (
+	"encoding/binary"
+
+	"golang.org/x/crypto/chacha20"
+)
+
+// NewDeterministic returns a CachedReader that caches size bytes at a time of a
+// ChaCha20 keystream whose key is derived from seed.  Readers created with the
+// same seed, size, and options serve the same sequence of bytes, so tests that
+// generate UUIDs can reproduce them exactly.  The sequence is only
+// reproducible if the reader is read by a single goroutine.
+//
+// The output of a deterministic reader is predictable by anyone who knows the
+// seed and must never be used outside of tests.
+func NewDeterministic(seed int64, size int, opts ...Option) (*CachedReader, error) {
+	var key [chacha20.KeySize]byte
+	binary.LittleEndian.PutUint64(key[:], uint64(seed))
+	var nonce [chacha20.NonceSize]byte
+	c, err := chacha20.NewUnauthenticatedCipher(key[:], nonce[:])
+	if err != nil {
+		return nil, err
+	}
+	// The keys of src are read from the keystream of the seed, so src never
+	// exhausts a single key.
+	src := &chacha20Reader{src: keystream{c}, interval: maxChaCha20Interval}
+	return New(src, size, opts...)
+}
+
+// A keystream reads the keystream of a ChaCha20 cipher.
+type keystream struct {
+	c *chacha20.Cipher
+}
+
+func (k keystream) Read(buf []byte) (int, error) {
+	clear(buf)
+	k.c.XORKeyStream(buf, buf)
+	return len(buf), nil
+}
@@ -0,0 +1,34 @@
+package cachedrander
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestDeterministic(t *testing.T) {
+	read := func(seed int64) []byte {
+		r, err := NewDeterministic(seed, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		buf := make([]byte, 200)
+		for i := 0; i < len(buf); i += 16 {
+			if _, err := io.ReadFull(r, buf[i:min(i+16, len(buf))]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return buf
+	}
+	a, b := read(1), read(1)
+	if !bytes.Equal(a, b) {
+		t.Errorf("seed 1 served different data:\n%x\n%x", a, b)
+	}
+	if c := read(2); bytes.Equal(a, c) {
+		t.Errorf("seeds 1 and 2 served the same data")
+	}
+	if bytes.Equal(a[:64], a[64:128]) {
+		t.Errorf("pages repeated")
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !cachedranderdebug
+
+package cachedrander
+
+import "io"
+
+// A dupDetector detects data served twice.  It is only implemented when
+// building with the cachedranderdebug build tag.
+type dupDetector struct{}
+
+// newDupDetector returns nil, disabling duplicate detection.
+func newDupDetector() *dupDetector { return nil }
+
+// setSource does nothing.
+func (d *dupDetector) setSource(src io.Reader) {}
+
+// check does nothing.
+func (d *dupDetector) check(buf []byte) {}
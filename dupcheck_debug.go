@@ -0,0 +1,100 @@
+//go:build cachedranderdebug
+
+package cachedrander
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// When built with the cachedranderdebug build tag, every CachedReader whose
+// source is crypto/rand.Reader, such as those returned by NewUUIDReader and
+// Default, records the 16 byte blocks it serves in a rolling Bloom filter and
+// panics if the same block is served twice.  This verifies that the page size
+// is large enough to avoid the race described in the package documentation.
+// Other sources, such as the deterministic sources used by tests, may
+// legitimately repeat their output and are not checked.  A block is only
+// checked when it is served by a single copy, so the blocks at the end of a
+// page straddled by a read are not checked.
+
+const (
+	// dupBits is the number of bits in each Bloom filter.  The two filters
+	// use 1MB per CachedReader.
+	dupBits = 1 << 22
+
+	// dupBlocks is the number of blocks added to a filter before it is
+	// rotated, so each block is remembered for at least the next 512KB
+	// served.  At this load the chance of a false positive for each block
+	// is about 1 in 3 billion.
+	dupBlocks = 1 << 15
+
+	// dupHashes is the number of bits set for each block.
+	dupHashes = 8
+)
+
+// A dupDetector is a rolling Bloom filter of the recently served blocks.  The
+// current filter receives new blocks; blocks are checked against both filters.
+// The filters are allocated when first needed.
+type dupDetector struct {
+	enabled  atomic.Bool // the source is crypto/rand.Reader
+	mu       sync.Mutex
+	cur, old []uint64
+	n        int // blocks added to cur
+}
+
+// newDupDetector returns a new dupDetector, which is disabled until setSource
+// is called.
+func newDupDetector() *dupDetector {
+	return &dupDetector{}
+}
+
+// setSource enables d if src is crypto/rand.Reader and disables it otherwise.
+func (d *dupDetector) setSource(src io.Reader) {
+	d.enabled.Store(src == rand.Reader)
+}
+
+// check records each 16 byte block of buf and panics if it was already served.
+func (d *dupDetector) check(buf []byte) {
+	if !d.enabled.Load() {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cur == nil {
+		d.cur = make([]uint64, dupBits/64)
+		d.old = make([]uint64, dupBits/64)
+	}
+	for ; len(buf) >= 16; buf = buf[16:] {
+		// The blocks are random so they need not be hashed.
+		h1 := binary.LittleEndian.Uint64(buf)
+		h2 := binary.LittleEndian.Uint64(buf[8:]) | 1
+		if d.contains(d.cur, h1, h2) || d.contains(d.old, h1, h2) {
+			// Formatting a copy keeps buf from escaping.
+			panic(fmt.Sprintf("cachedrander: block %x served twice", [16]byte(buf)))
+		}
+		for i := uint64(0); i < dupHashes; i++ {
+			bit := (h1 + i*h2) % dupBits
+			d.cur[bit/64] |= 1 << (bit % 64)
+		}
+		if d.n++; d.n == dupBlocks {
+			d.cur, d.old = d.old, d.cur
+			clear(d.cur)
+			d.n = 0
+		}
+	}
+}
+
+// contains reports whether the block with hashes h1 and h2 is in filter.
+func (d *dupDetector) contains(filter []uint64, h1, h2 uint64) bool {
+	for i := uint64(0); i < dupHashes; i++ {
+		bit := (h1 + i*h2) % dupBits
+		if filter[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
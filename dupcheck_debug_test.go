@@ -0,0 +1,46 @@
+//go:build cachedranderdebug
+
+package cachedrander
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestDupDetector(t *testing.T) {
+	r, err := NewUUIDReader(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if _, err := r.Read16(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := newDupDetector()
+	d.setSource(rand.Reader)
+	block := make([]byte, 16)
+	rand.Read(block)
+	d.check(block)
+	defer func() {
+		if recover() == nil {
+			t.Error("serving a block twice did not panic")
+		}
+	}()
+	d.check(bytes.Clone(block))
+}
+
+func TestDupDetectorTestSource(t *testing.T) {
+	// gen repeats its output every 256 bytes.
+	r, err := New(&gen{size: 256}, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if _, err := r.Read16(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package cachedrander
+
+import (
+	"crypto/rand"
+
+	"golang.org/x/crypto/chacha20"
+)
+
+// A pageKey is the XChaCha20 key and nonce a page is encrypted with.  A new
+// pageKey is used each time a page is loaded.
+type pageKey struct {
+	key   [chacha20.KeySize]byte
+	nonce [chacha20.NonceSizeX]byte
+}
+
+// WithEncryptedPages causes pages to be kept encrypted in memory with XChaCha20,
+// under a new random key for each page load, and decrypted only as they are
+// read.  At any instant the only unencrypted data is that being copied to
+// callers, limiting what a memory disclosure can reveal to roughly one Read's
+// worth.  Each Read must set up the cipher, so Reads are considerably slower.
+// Next returns a decrypted copy of the data, which is zeroed by release, rather
+// than a slice of the page.
+func WithEncryptedPages() Option {
+	return func(r *CachedReader) {
+		r.encrypt = true
+	}
+}
+
+// encryptPage encrypts the data in b in place under a new key.
+func encryptPage(b *buffer) error {
+	k := new(pageKey)
+	if _, err := rand.Read(k.key[:]); err != nil {
+		return err
+	}
+	if _, err := rand.Read(k.nonce[:]); err != nil {
+		return err
+	}
+	c, err := chacha20.NewUnauthenticatedCipher(k.key[:], k.nonce[:])
+	if err != nil {
+		return err
+	}
+	c.XORKeyStream(b.data, b.data)
+	b.key.Store(k)
+	return nil
+}
+
+// decrypt decrypts buf, which was copied from offset off of a page encrypted
+// with k.
+func (k *pageKey) decrypt(buf []byte, off uint64) {
+	c, _ := chacha20.NewUnauthenticatedCipher(k.key[:], k.nonce[:])
+	c.SetCounter(uint32(off / 64))
+	if skip := off % 64; skip != 0 {
+		var block [64]byte
+		c.XORKeyStream(block[:skip], block[:skip])
+	}
+	c.XORKeyStream(buf, buf)
+}
@@ -0,0 +1,43 @@
+package cachedrander
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncryptedPages(t *testing.T) {
+	r, err := New(&gen{size: 17}, 1024, WithEncryptedPages())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The page must not hold the plaintext.
+	if p := r.cur.Load(); bytes.Equal(p.data[:17], []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}) {
+		t.Fatal("page is not encrypted")
+	}
+	r.Max = 8
+	checkSequential(t, r)
+
+	r, err = New(&gen{size: 256}, 256, WithEncryptedPages(), WithPreformedUUIDs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := r.Read16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u[6]>>4 != 4 || u[8]>>6 != 2 {
+		t.Errorf("%x is not a version 4 UUID", u)
+	}
+	b, release, err := r.Next(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{16, 17, 18, 19, 20, 21, 0x40 | 22&0xf, 23, 0x80 | 24&0x3f, 25, 26, 27, 28, 29, 30, 31}
+	if !bytes.Equal(b, want) {
+		t.Errorf("Next got %x, want %x", b, want)
+	}
+	release()
+	if !bytes.Equal(b, make([]byte, 16)) {
+		t.Errorf("release did not zero %x", b)
+	}
+}
@@ -0,0 +1,134 @@
+package cachedrander
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// envOptions are the environment variables read by NewFromEnv and the
+// functions that convert their values to options.  A nil Option means the
+// variable requests nothing.
+var envOptions = []struct {
+	name  string
+	parse func(string) (Option, error)
+}{
+	{"CACHEDRANDER_PAGES", envInt(WithPageCount)},
+	{"CACHEDRANDER_MAX", func(s string) (Option, error) {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+		if n <= 0 {
+			return nil, fmt.Errorf("%d is not positive", n)
+		}
+		return WithMax(n), nil
+	}},
+	{"CACHEDRANDER_BACKGROUND_FILL", func(s string) (Option, error) {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, err
+		}
+		return WithBackgroundFill(f), nil
+	}},
+	{"CACHEDRANDER_WARM_STANDBY", envBool(WithWarmStandby)},
+	{"CACHEDRANDER_PARALLEL_FILL", envInt(WithParallelFill)},
+	{"CACHEDRANDER_FILL_TIMEOUT", envDuration(WithFillTimeout)},
+	{"CACHEDRANDER_MAX_PAGE_AGE", envDuration(WithMaxPageAge)},
+	{"CACHEDRANDER_CHACHA20", func(s string) (Option, error) {
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return WithChaCha20(n), nil
+	}},
+	{"CACHEDRANDER_FORK_DETECTION", envBool(WithForkDetection)},
+	{"CACHEDRANDER_FALLBACK", envBool(WithFallback)},
+	{"CACHEDRANDER_LOCKED_PAGES", envBool(WithLockedPages)},
+	{"CACHEDRANDER_HUGE_PAGES", envBool(WithHugePages)},
+}
+
+// NewFromEnv returns a CachedReader that caches data from crypto/rand.Reader,
+// configured by environment variables so the cache can be tuned for each
+// deployment without changing code.  The variables, which are ignored when
+// unset or empty, are:
+//
+//	CACHEDRANDER_SIZE             page size in bytes (default 16000)
+//	CACHEDRANDER_PAGES            WithPageCount
+//	CACHEDRANDER_MAX              WithMax
+//	CACHEDRANDER_BACKGROUND_FILL  WithBackgroundFill, a fraction such as 0.75
+//	CACHEDRANDER_WARM_STANDBY     WithWarmStandby, a boolean
+//	CACHEDRANDER_PARALLEL_FILL    WithParallelFill
+//	CACHEDRANDER_FILL_TIMEOUT     WithFillTimeout, a duration such as 50ms
+//	CACHEDRANDER_MAX_PAGE_AGE     WithMaxPageAge, a duration
+//	CACHEDRANDER_CHACHA20         WithChaCha20, the reseed interval in bytes
+//	CACHEDRANDER_FORK_DETECTION   WithForkDetection, a boolean
+//	CACHEDRANDER_FALLBACK         WithFallback, a boolean
+//	CACHEDRANDER_LOCKED_PAGES     WithLockedPages, a boolean
+//	CACHEDRANDER_HUGE_PAGES       WithHugePages, a boolean
+//
+// Booleans are parsed by strconv.ParseBool.  The options set by the
+// environment are applied after opts, and so override them.  NewFromEnv
+// returns an error naming the variable if a value cannot be parsed or
+// CACHEDRANDER_MAX is not positive.
+func NewFromEnv(opts ...Option) (*CachedReader, error) {
+	size := DefaultUUIDs * 16
+	if s := os.Getenv("CACHEDRANDER_SIZE"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("cachedrander: CACHEDRANDER_SIZE: %w", err)
+		}
+		size = n
+	}
+	opts = opts[:len(opts):len(opts)]
+	for _, e := range envOptions {
+		s := os.Getenv(e.name)
+		if s == "" {
+			continue
+		}
+		opt, err := e.parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("cachedrander: %s: %w", e.name, err)
+		}
+		if opt != nil {
+			opts = append(opts, opt)
+		}
+	}
+	return New(rand.Reader, size, opts...)
+}
+
+// envInt returns a parser for an integer passed to with.
+func envInt(with func(int) Option) func(string) (Option, error) {
+	return func(s string) (Option, error) {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+		return with(n), nil
+	}
+}
+
+// envDuration returns a parser for a duration passed to with.
+func envDuration(with func(time.Duration) Option) func(string) (Option, error) {
+	return func(s string) (Option, error) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, err
+		}
+		return with(d), nil
+	}
+}
+
+// envBool returns a parser for a boolean that requests the option returned by
+// with when true.
+func envBool(with func() Option) func(string) (Option, error) {
+	return func(s string) (Option, error) {
+		b, err := strconv.ParseBool(s)
+		if err != nil || !b {
+			return nil, err
+		}
+		return with(), nil
+	}
+}
@@ -0,0 +1,49 @@
+package cachedrander
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv("CACHEDRANDER_SIZE", "640")
+	t.Setenv("CACHEDRANDER_PAGES", "3")
+	t.Setenv("CACHEDRANDER_MAX", "32")
+	t.Setenv("CACHEDRANDER_BACKGROUND_FILL", "0.5")
+	t.Setenv("CACHEDRANDER_FILL_TIMEOUT", "50ms")
+	t.Setenv("CACHEDRANDER_FALLBACK", "false")
+	r, err := NewFromEnv(WithMax(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r.size.Load() != 640 {
+		t.Errorf("got size %d, want 640", r.size.Load())
+	}
+	if len(r.bufs) != 3 {
+		t.Errorf("got %d pages, want 3", len(r.bufs))
+	}
+	if r.Max != 32 {
+		t.Errorf("got Max %d, want 32", r.Max)
+	}
+	if r.fillAt != 0.5 {
+		t.Errorf("got background fill at %v, want 0.5", r.fillAt)
+	}
+	if r.fillTimeout != 50*time.Millisecond {
+		t.Errorf("got fill timeout %v, want 50ms", r.fillTimeout)
+	}
+	if r.fallback {
+		t.Errorf("fallback enabled by false")
+	}
+
+	t.Setenv("CACHEDRANDER_FILL_TIMEOUT", "soon")
+	if _, err := NewFromEnv(); err == nil || !strings.Contains(err.Error(), "CACHEDRANDER_FILL_TIMEOUT") {
+		t.Errorf("got error %v, want one naming CACHEDRANDER_FILL_TIMEOUT", err)
+	}
+	t.Setenv("CACHEDRANDER_FILL_TIMEOUT", "")
+	t.Setenv("CACHEDRANDER_MAX", "-3")
+	if _, err := NewFromEnv(); err == nil || !strings.Contains(err.Error(), "CACHEDRANDER_MAX") {
+		t.Errorf("got error %v, want one naming CACHEDRANDER_MAX", err)
+	}
+}
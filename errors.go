@@ -0,0 +1,36 @@
+package cachedrander
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrFillFailed matches, using errors.Is, every error returned when a page
+// could not be loaded from the source.  Such errors are FillErrors.
+var ErrFillFailed = errors.New("cachedrander: page load failed")
+
+// ErrShortSource is wrapped by the FillError returned when the source reached
+// end of file before filling a page.
+var ErrShortSource = errors.New("cachedrander: source returned too little data")
+
+// A FillError is returned when a page could not be loaded from the source.  It
+// wraps the error returned by the source and matches ErrFillFailed.
+type FillError struct {
+	Page   uint64 // the generation of the page being loaded
+	Offset int    // the bytes of the page that were read
+	Err    error  // the error returned by the source
+}
+
+func (e *FillError) Error() string {
+	return fmt.Sprintf("cachedrander: loading page %d failed at offset %d: %v", e.Page, e.Offset, e.Err)
+}
+
+// Unwrap returns the error returned by the source.
+func (e *FillError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrFillFailed.
+func (e *FillError) Is(target error) bool {
+	return target == ErrFillFailed
+}
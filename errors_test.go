@@ -0,0 +1,41 @@
+package cachedrander
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestFillError(t *testing.T) {
+	f := &flakyReader{}
+	r, err := New(f, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.fail = true
+	var buf [16]byte
+	r.Read(buf[:])
+	_, err = r.Read(buf[:])
+	if !errors.Is(err, ErrFillFailed) {
+		t.Fatalf("got error %v, want %v", err, ErrFillFailed)
+	}
+	var fe *FillError
+	if !errors.As(err, &fe) {
+		t.Fatalf("got error %T, want *FillError", err)
+	}
+	if fe.Page != 1 || fe.Offset != 0 || fe.Err.Error() != "flaky" {
+		t.Errorf("got %+v", fe)
+	}
+}
+
+func TestShortSource(t *testing.T) {
+	_, err := New(bytes.NewReader(make([]byte, 10)), 16)
+	if !errors.Is(err, ErrShortSource) || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("got error %v, want %v", err, ErrShortSource)
+	}
+	var fe *FillError
+	if !errors.As(err, &fe) || fe.Offset != 10 {
+		t.Errorf("got %v, want a FillError at offset 10", err)
+	}
+}
@@ -0,0 +1,75 @@
+package cachedrander
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// An ExportFormat is a format written by Export.
+type ExportFormat int
+
+const (
+	// ExportRaw is the raw bytes, as read by dieharder's file_input_raw
+	// generator (-g 201) and the binary input mode of the NIST Statistical
+	// Test Suite (assess).
+	ExportRaw ExportFormat = iota
+
+	// ExportDieharder is the text format read by dieharder's file_input
+	// generator (-g 202): a header followed by one unsigned 32 bit integer,
+	// in decimal, per line.  Only a multiple of 4 bytes is exported.
+	ExportDieharder
+
+	// ExportASCIIBits is a string of "0" and "1" characters, most
+	// significant bit of each byte first, as read by the ASCII input mode
+	// of the NIST Statistical Test Suite.
+	ExportASCIIBits
+)
+
+// Export writes n bytes of data read from r to w in format, so the output of
+// the cache can be validated with statistical test suites such as dieharder
+// and the NIST Statistical Test Suite.  The data is read with Reads of the
+// largest size r serves, as an application would, so any correlation or
+// truncation introduced by the cache is visible to the tests.
+func (r *CachedReader) Export(w io.Writer, n int64, format ExportFormat) error {
+	if format < ExportRaw || format > ExportASCIIBits {
+		return fmt.Errorf("cachedrander: unknown export format %d", format)
+	}
+	bw := bufio.NewWriter(w)
+	if format == ExportDieharder {
+		n -= n % 4
+		fmt.Fprintf(bw, "#==================================================================\n")
+		fmt.Fprintf(bw, "# generator cachedrander\n")
+		fmt.Fprintf(bw, "#==================================================================\n")
+		fmt.Fprintf(bw, "type: d\ncount: %d\nnumbit: 32\n", n/4)
+	}
+	// Reads are made in multiples of 4 so that each number written in the
+	// dieharder format is whole.
+	buf := make([]byte, max(r.maxLen()&^3, 4))
+	var line []byte
+	for n > 0 {
+		b := buf[:min(int64(len(buf)), n)]
+		if _, err := io.ReadFull(r, b); err != nil {
+			return err
+		}
+		n -= int64(len(b))
+		switch format {
+		case ExportRaw:
+			bw.Write(b)
+		case ExportDieharder:
+			for ; len(b) >= 4; b = b[4:] {
+				line = strconv.AppendUint(line[:0], uint64(binary.BigEndian.Uint32(b)), 10)
+				bw.Write(append(line, '\n'))
+			}
+		case ExportASCIIBits:
+			for _, c := range b {
+				for i := 7; i >= 0; i-- {
+					bw.WriteByte('0' + c>>i&1)
+				}
+			}
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,45 @@
+package cachedrander
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	for _, tt := range []struct {
+		format ExportFormat
+		n      int64
+		want   string
+	}{
+		{ExportRaw, 5, "\x00\x01\x02\x03\x04"},
+		{ExportDieharder, 9, "type: d\ncount: 2\nnumbit: 32\n66051\n67438087\n"},
+		{ExportASCIIBits, 3, "000000000000000100000010"},
+	} {
+		r, err := New(&gen{size: 64}, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := r.Export(&buf, tt.n, tt.format); err != nil {
+			t.Fatal(err)
+		}
+		got := buf.String()
+		if tt.format == ExportDieharder {
+			if !strings.HasPrefix(got, "#") {
+				t.Errorf("dieharder output has no header:\n%s", got)
+			}
+			got = got[strings.Index(got, "type:"):]
+		}
+		if got != tt.want {
+			t.Errorf("format %d: got %q, want %q", tt.format, got, tt.want)
+		}
+	}
+	r, err := New(&gen{size: 64}, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Export(&bytes.Buffer{}, 16, ExportASCIIBits+1); err == nil {
+		t.Errorf("unknown format did not return an error")
+	}
+}
@@ -0,0 +1,12 @@
+package cachedrander
+
+import "expvar"
+
+// PublishExpvar publishes r's Stats as the expvar variable name so they appear
+// in /debug/vars.  Like expvar.Publish, PublishExpvar panics if name is already
+// in use.
+func (r *CachedReader) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return r.Stats()
+	}))
+}
@@ -0,0 +1,26 @@
+package cachedrander
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	r, err := New(&gen{size: 17}, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.PublishExpvar("cachedrander_test")
+	v := expvar.Get("cachedrander_test")
+	if v == nil {
+		t.Fatal("variable not published")
+	}
+	var s Stats
+	if err := json.Unmarshal([]byte(v.String()), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Fills != 1 {
+		t.Errorf("Fills got %d, want 1", s.Fills)
+	}
+}
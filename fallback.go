@@ -0,0 +1,46 @@
+package cachedrander
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"log/slog"
+)
+
+// WithFallback causes Read, Read16, ReadN and the other methods that copy
+// cached data to read directly from crypto/rand.Reader when the next page
+// cannot be loaded, such as when the source returns an error or the fill
+// timeout expires, rather than failing.  Each such read is counted in
+// Stats.Fallbacks.  With WithPreformedUUIDs the version and variant bits of
+// each 16 byte block read from crypto/rand.Reader are set as well.  Reads
+// still fail when r is closed or their context is done.
+func WithFallback() Option {
+	return func(r *CachedReader) {
+		r.fallback = true
+	}
+}
+
+// fallBack fills buf directly from crypto/rand.Reader if WithFallback was used
+// and err, returned while waiting for a page, permits it.  It reports whether
+// buf was filled.
+func (r *CachedReader) fallBack(err error, buf []byte) bool {
+	if !r.fallback || errors.Is(err, ErrClosed) ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	// Reading into a separate buffer keeps buf from escaping, which would
+	// add an allocation to every read.
+	tmp := make([]byte, len(buf))
+	if _, err := io.ReadFull(rand.Reader, tmp); err != nil {
+		return false
+	}
+	if r.preformed {
+		preform(tmp)
+	}
+	copy(buf, tmp)
+	r.record(buf)
+	r.fallbacks.Add(1)
+	r.log(slog.LevelWarn, "cachedrander: read served from crypto/rand", "error", err)
+	return true
+}
@@ -0,0 +1,60 @@
+package cachedrander
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFallback(t *testing.T) {
+	f := &flakyReader{}
+	r, err := New(f, 16, WithFallback())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.fail = true
+	var buf [16]byte
+	for i := 0; i < 3; i++ {
+		if _, err := r.Read(buf[:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The flakyReader only returns 1s.
+	if bytes.Equal(buf[:], bytes.Repeat([]byte{1}, 16)) {
+		t.Error("fallback data came from the source")
+	}
+	if _, err := r.Read16(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.ReadN(buf[:], 1); err != nil {
+		t.Fatal(err)
+	}
+	if s := r.Stats(); s.Fallbacks != 4 {
+		t.Errorf("got %d fallbacks, want 4", s.Fallbacks)
+	}
+	r.Close()
+	if _, err := r.Read(buf[:]); err != ErrClosed {
+		t.Errorf("got error %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestFallbackPreformed(t *testing.T) {
+	f := &flakyReader{}
+	r, err := New(f, 64, WithFallback(), WithPreformedUUIDs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	f.fail = true
+	for i := 0; i < 50; i++ {
+		b, err := r.Read16()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b[6]>>4 != 4 || b[8]>>6 != 2 {
+			t.Errorf("UUID %d: %x is not a version 4 UUID", i, b)
+		}
+	}
+	if s := r.Stats(); s.Fallbacks == 0 {
+		t.Error("no reads were served from crypto/rand")
+	}
+}
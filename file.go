@@ -0,0 +1,86 @@
+package cachedrander
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// ErrEmptyFile is returned by OpenFileSource when asked to loop over an empty
+// file.
+var ErrEmptyFile = errors.New("cachedrander: file is empty")
+
+// A FileSource is a source that reads a file of random data, such as one
+// captured from crypto/rand ahead of time, for test rigs and benchmarks that
+// must not use the operating system's random number generator.  A FileSource
+// that loops starts over at the beginning of the file when it reaches the end,
+// and so serves the same data again: it must only be used where repeated IDs
+// are acceptable.  A FileSource that does not loop returns io.EOF at the end of
+// the file.  A FileSource is safe for concurrent use.
+type FileSource struct {
+	mu    sync.Mutex
+	f     *os.File
+	loop  bool
+	off   int64  // offset of the next byte to read
+	loops uint64 // times the end of the file was reached
+}
+
+// OpenFileSource opens the named file as a FileSource.  The source only loops
+// if loop is true.
+func OpenFileSource(name string, loop bool) (*FileSource, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if loop {
+		fi, err := f.Stat()
+		if err == nil && fi.Size() == 0 {
+			err = &os.PathError{Op: "open", Path: name, Err: ErrEmptyFile}
+		}
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return &FileSource{f: f, loop: loop}, nil
+}
+
+// Read fills buf with the next len(buf) bytes of the file, starting over at the
+// beginning of the file if s loops.
+func (s *FileSource) Read(buf []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for n < len(buf) {
+		c, err := s.f.ReadAt(buf[n:], s.off)
+		n += c
+		s.off += int64(c)
+		switch {
+		case err == io.EOF && s.loop && s.off > 0:
+			s.off = 0
+			s.loops++
+		case err == io.EOF && s.loop:
+			// The file was truncated while in use.
+			return n, io.ErrUnexpectedEOF
+		case err == io.EOF && n > 0:
+			return n, nil
+		case err != nil:
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Loops returns the number of times s has started over at the beginning of
+// the file.
+func (s *FileSource) Loops() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loops
+}
+
+// Close closes the file.
+func (s *FileSource) Close() error {
+	return s.f.Close()
+}
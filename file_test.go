@@ -0,0 +1,59 @@
+package cachedrander
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSource(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "random")
+	data := []byte("0123456789")
+	if err := os.WriteFile(name, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := OpenFileSource(name, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	buf := make([]byte, 25)
+	if _, err := io.ReadFull(s, buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := bytes.Repeat(data, 3)[:25]; !bytes.Equal(buf, want) {
+		t.Errorf("got %q, want %q", buf, want)
+	}
+	if n := s.Loops(); n != 2 {
+		t.Errorf("got %d loops, want 2", n)
+	}
+
+	s, err = OpenFileSource(name, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	got, err := io.ReadAll(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("got %q, want %q", got, data)
+	}
+	if _, err := New(s, 16); !errors.Is(err, ErrShortSource) {
+		t.Errorf("got error %v, want %v", err, ErrShortSource)
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenFileSource(empty, true); !errors.Is(err, ErrEmptyFile) {
+		t.Errorf("got error %v, want %v", err, ErrEmptyFile)
+	}
+}
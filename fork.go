@@ -0,0 +1,85 @@
+package cachedrander
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// forkState tracks forks of the process.  gen is incremented each time a fork
+// is detected.  On Linux, word points to memory marked MADV_WIPEONFORK, which
+// the kernel zeros in the child of a fork.
+var forkState struct {
+	once sync.Once
+	mu   sync.Mutex
+	gen  atomic.Uint64
+	pid  int
+	word *uint32
+}
+
+// initFork initializes forkState.
+func initFork() {
+	forkState.once.Do(func() {
+		forkState.pid = os.Getpid()
+		forkState.word = wipeOnForkWord()
+	})
+}
+
+// forkGeneration returns the number of forks detected so far.  It is cheap
+// enough to be called on every Read.
+func forkGeneration() uint64 {
+	if w := forkState.word; w != nil && atomic.LoadUint32(w) == 0 {
+		forkState.mu.Lock()
+		if atomic.LoadUint32(w) == 0 {
+			forkState.pid = os.Getpid()
+			forkState.gen.Add(1)
+			atomic.StoreUint32(w, 1)
+		}
+		forkState.mu.Unlock()
+	}
+	return forkState.gen.Load()
+}
+
+// checkPID detects forks by a change of process ID, which is too expensive to
+// check on every Read.  It returns the result of forkGeneration.
+func checkPID() uint64 {
+	forkState.mu.Lock()
+	if pid := os.Getpid(); pid != forkState.pid {
+		forkState.pid = pid
+		forkState.gen.Add(1)
+	}
+	forkState.mu.Unlock()
+	return forkGeneration()
+}
+
+// WithForkDetection causes the CachedReader to discard its cached data and
+// reseed itself when the process forks, so the child of a fork never serves
+// the same data as its parent.  On Linux a fork is detected on the next Read
+// using memory marked MADV_WIPEONFORK.  Elsewhere, or on kernels older than
+// 4.14, forks are detected by a change in process ID the next time a page is
+// loaded, which bounds the data shared with the parent to the cached pages.
+func WithForkDetection() Option {
+	return func(r *CachedReader) {
+		initFork()
+		r.detectFork = true
+		r.forkGen.Store(forkGeneration())
+	}
+}
+
+// checkFork reseeds r if the process has forked since r was last seeded.
+func (r *CachedReader) checkFork() error {
+	if !r.detectFork || forkGeneration() == r.forkGen.Load() {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrClosed
+	}
+	if gen := forkGeneration(); gen != r.forkGen.Load() {
+		r.discard()
+		r.forkGen.Store(gen)
+		return r.advance()
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package cachedrander
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// wipeOnForkWord returns a pointer to a word, set to 1, that the kernel will
+// zero in the child of a fork.  It returns nil if MADV_WIPEONFORK is not
+// supported.
+func wipeOnForkWord() *uint32 {
+	page, err := unix.Mmap(-1, 0, unix.Getpagesize(), unix.PROT_READ|unix.PROT_WRITE, unix.MAP_PRIVATE|unix.MAP_ANONYMOUS)
+	if err != nil {
+		return nil
+	}
+	if err := unix.Madvise(page, unix.MADV_WIPEONFORK); err != nil {
+		unix.Munmap(page)
+		return nil
+	}
+	w := (*uint32)(unsafe.Pointer(&page[0]))
+	*w = 1
+	return w
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package cachedrander
+
+// wipeOnForkWord returns nil as MADV_WIPEONFORK is only supported on Linux.
+func wipeOnForkWord() *uint32 {
+	return nil
+}
@@ -0,0 +1,45 @@
+package cachedrander
+
+import (
+	"io"
+	"sync/atomic"
+	"testing"
+)
+
+// simulateFork makes it appear that the process has forked.
+func simulateFork() {
+	if w := forkState.word; w != nil {
+		atomic.StoreUint32(w, 0)
+		return
+	}
+	forkState.mu.Lock()
+	forkState.pid = -1
+	forkState.mu.Unlock()
+}
+
+func TestForkDetection(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64, WithForkDetection())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [16]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	next := g.head
+	simulateFork()
+	if forkState.word == nil {
+		// Without MADV_WIPEONFORK the fork is only noticed when the
+		// next page is loaded.
+		for i := 0; i < 2; i++ {
+			io.ReadFull(r, buf[:])
+		}
+	}
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	if buf[0] != byte(next) {
+		t.Fatalf("got byte %d, want %d from a reseeded page", buf[0], byte(next))
+	}
+}
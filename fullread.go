@@ -0,0 +1,46 @@
+package cachedrander
+
+import (
+	"context"
+	"io"
+)
+
+// WithFullReads changes Read to always fill its entire buffer, as io.ReadFull
+// would, rather than truncating reads to Max bytes or to the end of the current
+// page.  This suits callers such as io.Copy and bufio that expect a Reader to
+// honor large reads.  Reads larger than a page bypass the cache and are read
+// directly from the source.  Max is ignored.
+func WithFullReads() Option {
+	return func(r *CachedReader) {
+		r.fullReads = true
+	}
+}
+
+// readAll fills buf from the cache, or directly from the source if buf is
+// larger than a page.
+func (r *CachedReader) readAll(ctx context.Context, buf []byte) (int, error) {
+	if uint64(len(buf)) > r.size.Load() {
+		return r.readSource(r.r, buf)
+	}
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	if err := r.readFull(ctx, buf); err != nil {
+		return 0, err
+	}
+	return len(buf), nil
+}
+
+// readSource fills buf directly from src, which is r.r or r.src.
+func (r *CachedReader) readSource(src io.Reader, buf []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return 0, ErrClosed
+	}
+	n, err := io.ReadFull(src, buf)
+	r.served.Add(uint64(n))
+	r.dups.check(buf[:n])
+	r.record(buf[:n])
+	return n, err
+}
@@ -0,0 +1,35 @@
+package cachedrander
+
+import "testing"
+
+func TestFullReads(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64, WithFullReads())
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := 0
+	for _, size := range []int{8, 40, 20, 64, 100, 1, 200} {
+		buf := make([]byte, size)
+		n, err := r.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != size {
+			t.Fatalf("Read(%d) returned %d bytes", size, n)
+		}
+		want := &next
+		if size > 64 {
+			// Read directly from the source, bypassing the
+			// cache.
+			direct := g.head - size
+			want = &direct
+		}
+		for i, b := range buf {
+			if b != byte(*want) {
+				t.Fatalf("Read(%d) byte %d: got %d, want %d", size, i, b, byte(*want))
+			}
+			*want++
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package cachedrander
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// maxGetrandom is the most data a single getrandom(2) call will return.
+const maxGetrandom = 1<<25 - 1
+
+// A GetrandomReader is a source that reads directly from the kernel's random
+// number generator using getrandom(2), bypassing crypto/rand.  Each call to
+// Read makes as few system calls as possible, so it is best used with large
+// pages.  GetrandomReader is only available on Linux.
+type GetrandomReader struct {
+	// NonBlock causes Read to return an error, rather than block, if the
+	// kernel's random number generator has not yet been initialized.  This
+	// can only happen early in the boot process.
+	NonBlock bool
+}
+
+// Read fills buf from getrandom(2).
+func (g GetrandomReader) Read(buf []byte) (int, error) {
+	flags := 0
+	if g.NonBlock {
+		flags |= unix.GRND_NONBLOCK
+	}
+	n := 0
+	for n < len(buf) {
+		end := min(len(buf), n+maxGetrandom)
+		m, err := unix.Getrandom(buf[n:end], flags)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			return n, err
+		}
+		n += m
+	}
+	return n, nil
+}
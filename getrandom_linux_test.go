@@ -0,0 +1,22 @@
+package cachedrander
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGetrandomReader(t *testing.T) {
+	for _, g := range []GetrandomReader{{}, {NonBlock: true}} {
+		r, err := New(g, 1<<16)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf [16]byte
+		if _, err := r.Read(buf[:]); err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(buf[:], make([]byte, 16)) {
+			t.Error("got all zero data")
+		}
+	}
+}
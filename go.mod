@@ -1,3 +1,14 @@
 module github.com/pborman/cachedrander
 
-go 1.12
+go 1.23
+
+require (
+	github.com/awnumar/memguard v0.22.5
+	github.com/gofrs/uuid/v5 v5.3.0
+	github.com/google/uuid v1.6.0
+	github.com/segmentio/ksuid v1.0.4
+	golang.org/x/crypto v0.31.0
+	golang.org/x/sys v0.28.0
+)
+
+require github.com/awnumar/memcall v0.2.0 // indirect
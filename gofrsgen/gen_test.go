@@ -0,0 +1,63 @@
+package gofrsgen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/pborman/cachedrander"
+)
+
+func TestNewV4(t *testing.T) {
+	g, err := New(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[uuid.UUID]bool{}
+	for i := 0; i < 1000; i++ {
+		u, err := g.NewV4()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := u.Version(); v != uuid.V4 {
+			t.Fatalf("got version %d, want %d", v, uuid.V4)
+		}
+		if seen[u] {
+			t.Fatalf("duplicate UUID %v", u)
+		}
+		seen[u] = true
+	}
+}
+
+func TestNewV7(t *testing.T) {
+	r, err := cachedrander.NewUUIDReader(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	epoch := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	g := NewFromReader(r, uuid.WithEpochFunc(func() time.Time { return epoch }))
+	var last uuid.UUID
+	for i := 0; i < 100; i++ {
+		u, err := g.NewV7()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := u.Version(); v != uuid.V7 {
+			t.Fatalf("got version %d, want %d", v, uuid.V7)
+		}
+		ts, err := uuid.TimestampFromV7(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tm, _ := ts.Time(); !tm.Equal(epoch) {
+			t.Fatalf("got time %v, want %v", tm, epoch)
+		}
+		if u == last {
+			t.Fatalf("duplicate UUID %v", u)
+		}
+		last = u
+	}
+	if r.Stats().BytesServed == 0 {
+		t.Error("no data was read from the CachedReader")
+	}
+}
@@ -0,0 +1,58 @@
+package cachedrander
+
+import (
+	"context"
+	"io"
+)
+
+// Healthy returns nil if r is usable: it is not closed, the circuit breaker
+// (see WithCircuitBreaker) is not open, and the most recent attempt to load a
+// page succeeded.  Otherwise the reason is returned, such as the FillError
+// returned by the most recent load.  Healthy does not read from the source, or
+// wait for a page load in progress; use Ping to verify that the source is
+// responsive.
+func (r *CachedReader) Healthy() error {
+	r.statMu.Lock()
+	defer r.statMu.Unlock()
+	switch {
+	case r.closed:
+		return ErrClosed
+	case r.breaker.open():
+		return ErrCircuitOpen
+	}
+	return r.lastErr
+}
+
+// pingSize is the number of bytes read from the source by Ping.
+const pingSize = 16
+
+// Ping verifies that the source is responsive by reading a few bytes from it,
+// returning early with ctx.Err() if ctx is done first.  The source is read
+// directly, not through wrappers such as WithChaCha20 that would hide a hung
+// source.  The bytes read are discarded.  Unless WithParallelFill is used,
+// reads from the source are serialized, so Ping waits for any read of the
+// source in progress.  Concurrent calls to Ping share a single read of the
+// source, which continues after Ping returns early.
+func (r *CachedReader) Ping(ctx context.Context) error {
+	c := runAsync(&r.pinging, r.probe)
+	select {
+	case <-c.done:
+		return c.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// probe reads pingSize bytes from the unwrapped source for Ping.
+func (r *CachedReader) probe() error {
+	r.statMu.Lock()
+	src, closed := r.src, r.closed
+	r.statMu.Unlock()
+	if closed {
+		return ErrClosed
+	}
+	var buf [pingSize]byte
+	_, err := io.ReadFull(src, buf[:])
+	r.addWasted(pingSize)
+	return err
+}
@@ -0,0 +1,126 @@
+package cachedrander
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestHealthy(t *testing.T) {
+	f := &flakyReader{}
+	r, err := New(f, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Healthy(); err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+	if err := r.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping got error %v, want nil", err)
+	}
+	f.fail = true
+	if err := r.Ping(context.Background()); err == nil {
+		t.Fatal("Ping did not return the source's error")
+	}
+	var buf [16]byte
+	r.Read(buf[:])
+	r.Read(buf[:])
+	if err := r.Healthy(); !errors.Is(err, ErrFillFailed) {
+		t.Errorf("got error %v, want %v", err, ErrFillFailed)
+	}
+	f.fail = false
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Healthy(); err != nil {
+		t.Errorf("got error %v, want nil", err)
+	}
+	r.Close()
+	if err := r.Healthy(); err != ErrClosed {
+		t.Errorf("got error %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestPingTimeout(t *testing.T) {
+	s := &slowReader{release: make(chan struct{})}
+	close(s.release)
+	r, err := New(s, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.release = make(chan struct{})
+	defer close(s.release)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := r.Ping(ctx); err != context.DeadlineExceeded {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestHealthyHungSource(t *testing.T) {
+	s := &slowReader{release: make(chan struct{})}
+	close(s.release)
+	r, err := New(s, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.release = make(chan struct{})
+	defer close(s.release)
+	go func() {
+		// The second Read loads a page from the hung source.
+		var buf [16]byte
+		r.Read(buf[:])
+		r.Read(buf[:])
+	}()
+	for !r.loads.active.Load() {
+		time.Sleep(time.Millisecond)
+	}
+	done := make(chan struct{})
+	go func() {
+		r.Healthy()
+		r.Stats()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Healthy and Stats waited for the hung source")
+	}
+}
+
+func TestPingHungSource(t *testing.T) {
+	s := &slowReader{release: make(chan struct{})}
+	close(s.release)
+	// The ChaCha20 DRBG does not read its source again for a long time.
+	r, err := New(s, 16, WithChaCha20(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.release = make(chan struct{})
+	defer close(s.release)
+	before := runtime.NumGoroutine()
+	for i := 0; i < 20; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		if err := r.Ping(ctx); err != context.DeadlineExceeded {
+			t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+		}
+		cancel()
+	}
+	// Only the one probe of the hung source may be left running.
+	if n := runtime.NumGoroutine(); n > before+1 {
+		t.Errorf("%d goroutines left running after timed out Pings, want at most 1", n-before)
+	}
+	// The probe does not hold r.mu.
+	done := make(chan error)
+	go func() { done <- r.Resize(32) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Resize waited for the hung probe")
+	}
+}
@@ -0,0 +1,95 @@
+package cachedrander
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// HistogramBuckets is the number of buckets in a Histogram.
+const HistogramBuckets = 25
+
+// A Histogram counts durations in buckets whose bounds double from one
+// microsecond to about 8 seconds.  Bucket i counts the durations less than
+// HistogramBound(i) and, for i > 0, at least HistogramBound(i-1).  The last
+// bucket counts all the durations of at least HistogramBound(HistogramBuckets-2).
+type Histogram struct {
+	Counts [HistogramBuckets]uint64
+}
+
+// HistogramBound returns the upper bound of bucket i of a Histogram.  The
+// bound of the last bucket is the largest possible duration.
+func HistogramBound(i int) time.Duration {
+	if i >= HistogramBuckets-1 {
+		return math.MaxInt64
+	}
+	return time.Microsecond << i
+}
+
+// bucket returns the index of the Histogram bucket that counts d.
+func bucket(d time.Duration) int {
+	if d < time.Microsecond {
+		return 0
+	}
+	return min(bits.Len64(uint64(d/time.Microsecond)), HistogramBuckets-1)
+}
+
+// add counts d.
+func (h *Histogram) add(d time.Duration) {
+	h.Counts[bucket(d)]++
+}
+
+// Count returns the number of durations counted by h.
+func (h *Histogram) Count() uint64 {
+	var n uint64
+	for _, c := range h.Counts {
+		n += c
+	}
+	return n
+}
+
+// Quantile returns an upper bound on the q quantile (e.g., 0.99) of the
+// durations counted by h: the upper bound of the bucket holding it.  It
+// returns 0 if h is empty.
+func (h *Histogram) Quantile(q float64) time.Duration {
+	n := h.Count()
+	if n == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(q * float64(n)))
+	var seen uint64
+	for i, c := range h.Counts {
+		seen += c
+		if seen >= max(rank, 1) {
+			return HistogramBound(i)
+		}
+	}
+	return HistogramBound(HistogramBuckets - 1)
+}
+
+// An atomicHistogram is a Histogram that may be updated without a lock.  It
+// also tracks the longest duration added.
+type atomicHistogram struct {
+	counts [HistogramBuckets]atomic.Uint64
+	max    atomic.Int64
+}
+
+// add counts d.
+func (h *atomicHistogram) add(d time.Duration) {
+	h.counts[bucket(d)].Add(1)
+	for m := h.max.Load(); int64(d) > m; m = h.max.Load() {
+		if h.max.CompareAndSwap(m, int64(d)) {
+			break
+		}
+	}
+}
+
+// load returns a snapshot of h and the longest duration added to it.
+func (h *atomicHistogram) load() (Histogram, time.Duration) {
+	var s Histogram
+	for i := range h.counts {
+		s.Counts[i] = h.counts[i].Load()
+	}
+	return s, time.Duration(h.max.Load())
+}
@@ -0,0 +1,73 @@
+package cachedrander
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogram(t *testing.T) {
+	var h Histogram
+	if q := h.Quantile(0.5); q != 0 {
+		t.Errorf("empty Quantile got %v, want 0", q)
+	}
+	for _, tt := range []struct {
+		d      time.Duration
+		bucket int
+	}{
+		{0, 0},
+		{time.Microsecond - 1, 0},
+		{time.Microsecond, 1},
+		{3 * time.Microsecond, 2},
+		{4 * time.Microsecond, 3},
+		{time.Millisecond, 10},
+		{time.Second, 20},
+		{time.Hour, HistogramBuckets - 1},
+	} {
+		var h Histogram
+		h.add(tt.d)
+		if h.Counts[tt.bucket] != 1 {
+			t.Errorf("%v: got buckets %v, want bucket %d", tt.d, h.Counts, tt.bucket)
+			continue
+		}
+		if tt.d >= HistogramBound(tt.bucket) {
+			t.Errorf("%v: not below bound %v", tt.d, HistogramBound(tt.bucket))
+		}
+		if tt.bucket > 0 && tt.d < HistogramBound(tt.bucket-1) {
+			t.Errorf("%v: below previous bound %v", tt.d, HistogramBound(tt.bucket-1))
+		}
+	}
+
+	for i := 0; i < 99; i++ {
+		h.add(time.Millisecond)
+	}
+	h.add(time.Second)
+	if n := h.Count(); n != 100 {
+		t.Errorf("Count got %d, want 100", n)
+	}
+	if q, want := h.Quantile(0.5), HistogramBound(10); q != want {
+		t.Errorf("Quantile(0.5) got %v, want %v", q, want)
+	}
+	if q, want := h.Quantile(0.99), HistogramBound(10); q != want {
+		t.Errorf("Quantile(0.99) got %v, want %v", q, want)
+	}
+	if q, want := h.Quantile(1), HistogramBound(20); q != want {
+		t.Errorf("Quantile(1) got %v, want %v", q, want)
+	}
+}
+
+func TestAtomicHistogram(t *testing.T) {
+	var h atomicHistogram
+	h.add(time.Millisecond)
+	h.add(3 * time.Millisecond)
+	h.add(2 * time.Millisecond)
+	s, m := h.load()
+	if n := s.Count(); n != 3 {
+		t.Errorf("Count got %d, want 3", n)
+	}
+	if s.Counts[bucket(time.Millisecond)] != 1 {
+		t.Errorf("got buckets %v", s.Counts)
+	}
+	if m != 3*time.Millisecond {
+		t.Errorf("max got %v, want %v", m, 3*time.Millisecond)
+	}
+}
@@ -0,0 +1,16 @@
+package cachedrander
+
+// hugePageSize is the size of a huge page on the common platforms.
+const hugePageSize = 2 << 20
+
+// WithHugePages causes pages to be allocated outside of the Go heap backed by
+// huge pages, reducing TLB pressure for services that provision very large
+// caches.  On Linux explicit huge pages (MAP_HUGETLB) are used if the system
+// has reserved them, otherwise transparent huge pages are requested with
+// MADV_HUGEPAGE.  Each page is rounded up to a multiple of 2MB, so
+// WithHugePages is only worthwhile for pages of several megabytes.  On other
+// platforms pages are allocated normally.  WithHugePages replaces any
+// Allocator set by WithAllocator or WithLockedPages.
+func WithHugePages() Option {
+	return WithAllocator(hugeAllocator{})
+}
@@ -0,0 +1,38 @@
+package cachedrander
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// A hugeAllocator allocates pages for WithHugePages.
+type hugeAllocator struct{}
+
+// Alloc returns size bytes of anonymous memory backed by huge pages.
+func (hugeAllocator) Alloc(size int) ([]byte, error) {
+	length := (size + hugePageSize - 1) / hugePageSize * hugePageSize
+	const prot = unix.PROT_READ | unix.PROT_WRITE
+	const flags = unix.MAP_PRIVATE | unix.MAP_ANONYMOUS
+	b, err := unix.Mmap(-1, 0, length, prot, flags|unix.MAP_HUGETLB)
+	if err != nil {
+		// No explicit huge pages are available, fall back to
+		// transparent huge pages.
+		if b, err = unix.Mmap(-1, 0, length, prot, flags); err != nil {
+			return nil, fmt.Errorf("cachedrander: mmap: %w", err)
+		}
+		// Transparent huge pages may be disabled, in which case the
+		// memory is still usable.
+		unix.Madvise(b, unix.MADV_HUGEPAGE)
+	}
+	return b[:size], nil
+}
+
+// Free zeros and unmaps b, including the rest of the huge page beyond len(b).
+func (hugeAllocator) Free(b []byte) error {
+	clear(b)
+	if err := unix.Munmap(b[:cap(b)]); err != nil {
+		return fmt.Errorf("cachedrander: munmap: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package cachedrander
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHugePages(t *testing.T) {
+	r, err := New(&gen{size: 17}, 3<<20, WithHugePages())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := r.bufs[0].data
+	if len(b) != 3<<20 || cap(b) != 4<<20 {
+		t.Errorf("got len %d cap %d, want %d and %d", len(b), cap(b), 3<<20, 4<<20)
+	}
+	r.Max = 8
+	checkSequential(t, r)
+
+	// Explicit huge pages are mapped as hugetlb (ht) and transparent huge
+	// pages are advised (hg), unless disabled.
+	thp, _ := os.ReadFile("/sys/kernel/mm/transparent_hugepage/enabled")
+	flags := vmFlags(t, b)
+	if !slices.Contains(flags, "ht") && !slices.Contains(flags, "hg") && !strings.Contains(string(thp), "[never]") {
+		t.Errorf("page mapping flags %v include neither ht nor hg", flags)
+	}
+	r.Close()
+}
+
+func TestHugePagesFree(t *testing.T) {
+	var a hugeAllocator
+	b, err := a.Alloc(hugePageSize + 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Free(b); err != nil {
+		t.Errorf("Free of a partial huge page: %v", err)
+	}
+}
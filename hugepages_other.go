@@ -0,0 +1,15 @@
+//go:build !linux
+
+package cachedrander
+
+// A hugeAllocator allocates pages for WithHugePages.  Huge pages are only
+// supported on Linux, so it allocates pages on the Go heap.
+type hugeAllocator struct{}
+
+// Alloc returns size bytes of memory.
+func (hugeAllocator) Alloc(size int) ([]byte, error) {
+	return make([]byte, size), nil
+}
+
+// Free does nothing.
+func (hugeAllocator) Free(b []byte) error { return nil }
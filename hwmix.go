@@ -0,0 +1,85 @@
+package cachedrander
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// ErrNoHardwareRNG is returned by HardwareReader when the CPU does not have a
+// supported random number generator or the generator failed to return data.
+var ErrNoHardwareRNG = errors.New("cachedrander: hardware random number generator unavailable")
+
+// HasHardwareRNG reports whether the CPU has a random number generator usable
+// by WithHardwareMixing and HardwareReader.
+func HasHardwareRNG() bool {
+	return hasCPUSeed
+}
+
+// A HardwareReader is a source that reads directly from the CPU's random
+// number generator (see WithHardwareMixing).  It is intended for deployments
+// that want a hardware rooted source; it is typically much slower than
+// crypto/rand.
+type HardwareReader struct{}
+
+// Read fills buf from the CPU's random number generator.  It returns
+// ErrNoHardwareRNG if there is no such generator or it failed.
+func (HardwareReader) Read(buf []byte) (int, error) {
+	if !hasCPUSeed {
+		return 0, ErrNoHardwareRNG
+	}
+	for n := 0; n < len(buf); n += 8 {
+		v, ok := cpuSeed()
+		if !ok {
+			return n, ErrNoHardwareRNG
+		}
+		var w [8]byte
+		binary.LittleEndian.PutUint64(w[:], v)
+		copy(buf[n:], w[:])
+	}
+	return len(buf), nil
+}
+
+// WithHardwareMixing causes each page loaded from the source to be mixed (by
+// exclusive or) with random data from the CPU's hardware random number
+// generator.  This provides defense in depth: the pages are unpredictable as
+// long as either the source or the CPU is.  On amd64 the RDSEED instruction is
+// used.  On arm64 the RNDRRS register (FEAT_RNG, detected at runtime on Linux)
+// is used.  WithHardwareMixing has no effect on CPUs without a supported
+// instruction.  Words for which the CPU repeatedly fails to return data are left
+// unmixed.
+func WithHardwareMixing() Option {
+	return func(r *CachedReader) {
+		if hasCPUSeed {
+			r.wrap(func(src io.Reader) io.Reader {
+				return &hwMixer{r: src}
+			})
+		}
+	}
+}
+
+// An hwMixer mixes the CPU's random data into the data read from r.
+type hwMixer struct {
+	r io.Reader
+}
+
+func (h *hwMixer) Read(buf []byte) (int, error) {
+	n, err := h.r.Read(buf)
+	mixCPUSeed(buf[:n])
+	return n, err
+}
+
+// mixCPUSeed exclusive ors buf with data from cpuSeed.
+func mixCPUSeed(buf []byte) {
+	for len(buf) > 0 {
+		v, ok := cpuSeed()
+		var w [8]byte
+		if ok {
+			binary.LittleEndian.PutUint64(w[:], v)
+		}
+		for i := 0; i < len(w) && i < len(buf); i++ {
+			buf[i] ^= w[i]
+		}
+		buf = buf[min(len(buf), 8):]
+	}
+}
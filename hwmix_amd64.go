@@ -0,0 +1,14 @@
+package cachedrander
+
+import "golang.org/x/sys/cpu"
+
+var hasCPUSeed = cpu.X86.HasRDSEED
+
+// cpuSeed returns 64 bits from the RDSEED instruction.  It reports false if
+// RDSEED repeatedly failed to return data.
+func cpuSeed() (uint64, bool) {
+	return rdseed()
+}
+
+// rdseed is implemented in hwmix_amd64.s.
+func rdseed() (uint64, bool)
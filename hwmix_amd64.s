@@ -0,0 +1,20 @@
+#include "textflag.h"
+
+// func rdseed() (uint64, bool)
+TEXT ·rdseed(SB), NOSPLIT, $0-9
+	MOVQ $128, CX
+
+retry:
+	RDSEEDQ AX
+	JCS     ok
+	PAUSE
+	DECQ    CX
+	JNZ     retry
+	MOVQ    $0, ret+0(FP)
+	MOVB    $0, ret1+8(FP)
+	RET
+
+ok:
+	MOVQ AX, ret+0(FP)
+	MOVB $1, ret1+8(FP)
+	RET
@@ -0,0 +1,19 @@
+package cachedrander
+
+import "golang.org/x/sys/cpu"
+
+// hasCPUSeed reports whether the CPU implements FEAT_RNG.  The ID register
+// can only be read when the kernel emulates it, which x/sys/cpu reports as
+// HasCPUID.
+var hasCPUSeed = cpu.ARM64.HasCPUID && (isar0()>>60)&0xf >= 1
+
+// cpuSeed returns 64 bits from the RNDRRS register, which is reseeded from the
+// CPU's entropy source on each read.  It reports false if RNDRRS repeatedly
+// failed to return data.
+func cpuSeed() (uint64, bool) {
+	return rndrrs()
+}
+
+// rndrrs and isar0 are implemented in hwmix_arm64.s.
+func rndrrs() (uint64, bool)
+func isar0() uint64
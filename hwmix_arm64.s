@@ -0,0 +1,27 @@
+#include "textflag.h"
+
+// func rndrrs() (uint64, bool)
+TEXT ·rndrrs(SB), NOSPLIT, $0-9
+	MOVD $128, R1
+
+retry:
+	// On failure RNDRRS sets the Z flag.
+	MRS  RNDRRS, R0
+	BNE  ok
+	SUB  $1, R1
+	CBNZ R1, retry
+	MOVD ZR, ret+0(FP)
+	MOVB ZR, ret1+8(FP)
+	RET
+
+ok:
+	MOVD R0, ret+0(FP)
+	MOVD $1, R2
+	MOVB R2, ret1+8(FP)
+	RET
+
+// func isar0() uint64
+TEXT ·isar0(SB), NOSPLIT, $0-8
+	MRS  ID_AA64ISAR0_EL1, R0
+	MOVD R0, ret+0(FP)
+	RET
@@ -0,0 +1,9 @@
+//go:build !amd64 && !arm64
+
+package cachedrander
+
+const hasCPUSeed = false
+
+func cpuSeed() (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,45 @@
+package cachedrander
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestHardwareMixing(t *testing.T) {
+	r, err := New(bytes.NewReader(make([]byte, 64)), 64, WithHardwareMixing())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [16]byte
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	zero := buf == [16]byte{}
+	if hasCPUSeed && zero {
+		t.Error("data was not mixed")
+	}
+	if !hasCPUSeed && !zero {
+		t.Error("data was mixed without hardware support")
+	}
+}
+
+func TestHardwareReader(t *testing.T) {
+	r, err := New(HardwareReader{}, 64)
+	if !HasHardwareRNG() {
+		if !errors.Is(err, ErrNoHardwareRNG) {
+			t.Fatalf("got error %v, want %v", err, ErrNoHardwareRNG)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [16]byte
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	if buf == [16]byte{} {
+		t.Error("got all zero data")
+	}
+}
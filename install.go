@@ -0,0 +1,45 @@
+package cachedrander
+
+import (
+	"io"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// installed is the reader most recently installed by InstallUUID.  The uuid
+// package provides no way to retrieve its current reader, so readers set by
+// calling uuid.SetRand directly are not known.
+var installed struct {
+	mu sync.Mutex
+	r  io.Reader // nil for the uuid package's default
+}
+
+// InstallUUID creates a CachedReader that caches n UUIDs' worth of data from
+// crypto/rand.Reader and installs it with uuid.SetRand.  The returned uninstall
+// function closes the reader and reinstalls the reader that was installed by
+// the previous call to InstallUUID, or the uuid package's default reader.
+// Calls to uninstall should be made in the reverse order of the calls to
+// InstallUUID, as they would be when deferred in tests.
+func InstallUUID(n int, opts ...Option) (uninstall func(), err error) {
+	r, err := NewUUIDReader(n, opts...)
+	if err != nil {
+		return nil, err
+	}
+	installed.mu.Lock()
+	prev := installed.r
+	installed.r = r
+	uuid.SetRand(r)
+	installed.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			installed.mu.Lock()
+			installed.r = prev
+			uuid.SetRand(prev)
+			installed.mu.Unlock()
+			r.Close()
+		})
+	}, nil
+}
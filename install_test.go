@@ -0,0 +1,41 @@
+package cachedrander
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestInstallUUID(t *testing.T) {
+	uninstall, err := InstallUUID(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := installed.r.(*CachedReader)
+	uuid.New()
+	if s := first.Stats(); s.BytesServed != 16 {
+		t.Errorf("BytesServed got %d, want 16", s.BytesServed)
+	}
+
+	uninstall2, err := InstallUUID(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uninstall2()
+	if installed.r != first {
+		t.Error("uninstall did not restore the previous reader")
+	}
+	uuid.New()
+	if s := first.Stats(); s.BytesServed != 32 {
+		t.Errorf("BytesServed got %d, want 32", s.BytesServed)
+	}
+
+	uninstall()
+	uninstall()
+	if installed.r != nil {
+		t.Error("uninstall did not restore the default reader")
+	}
+	if _, err := uuid.NewRandom(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,42 @@
+package cachedrander
+
+import (
+	"context"
+	"iter"
+)
+
+// Blocks returns an iterator over an endless sequence of size byte blocks of
+// cached data.  The slice yielded is reused for each block, so it is only valid
+// until the next iteration.  Blocks may be larger than a page.  The sequence
+// ends if reading from r returns an error, such as after r is closed.
+//
+//	for b := range r.Blocks(32) {
+//		...
+//	}
+func (r *CachedReader) Blocks(size int) iter.Seq[[]byte] {
+	return func(yield func([]byte) bool) {
+		buf := make([]byte, size)
+		for {
+			if err := r.readChunks(context.Background(), buf); err != nil {
+				return
+			}
+			if !yield(buf) {
+				return
+			}
+		}
+	}
+}
+
+// UUIDs returns an iterator over an endless sequence of 16 byte blocks of
+// cached data, as returned by Read16.  The sequence ends if Read16 returns an
+// error.
+func (r *CachedReader) UUIDs() iter.Seq[[16]byte] {
+	return func(yield func([16]byte) bool) {
+		for {
+			b, err := r.Read16()
+			if err != nil || !yield(b) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package cachedrander
+
+import "testing"
+
+func TestBlocks(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	// Blocks are larger than a page.
+	for b := range r.Blocks(100) {
+		if len(b) != 100 {
+			t.Fatalf("block %d: got %d bytes, want 100", n, len(b))
+		}
+		for i, c := range b {
+			if want := byte(n*100 + i); c != want {
+				t.Fatalf("block %d byte %d: got %d, want %d", n, i, c, want)
+			}
+		}
+		if n++; n == 5 {
+			break
+		}
+	}
+}
+
+func TestUUIDs(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for b := range r.UUIDs() {
+		if want := byte(n * 16); b[0] != want || b[15] != want+15 {
+			t.Fatalf("UUID %d: got %v, want bytes starting at %d", n, b, want)
+		}
+		if n++; n == 3 {
+			r.Close()
+		}
+	}
+	if n != 3 {
+		t.Errorf("got %d UUIDs, want 3", n)
+	}
+}
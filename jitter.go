@@ -0,0 +1,91 @@
+package cachedrander
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrJitterStuck is returned by a JitterReader whose timer is too coarse to
+// measure the variation in its samples.
+var ErrJitterStuck = errors.New("cachedrander: jitter samples are stuck")
+
+// defaultJitterSamples assumes each timing sample provides at least 1/4 bit
+// of entropy.
+const defaultJitterSamples = 4 * 8 * sha256.Size
+
+// maxStuckSamples is the number of consecutive stuck samples, equal to the
+// previous sample, after which a JitterReader gives up, as the health test of
+// jitterentropy does.  Without it a clock coarser than a sample, such as the
+// jiffies clock of some virtual machines and embedded boards, would never
+// produce a sample that is not stuck.
+const maxStuckSamples = 1024
+
+// A JitterReader is a source that gathers entropy from the variation in the
+// time the CPU takes to execute a memory intensive loop, in the manner of
+// jitterentropy.  It needs no operating system or hardware support, making it
+// suitable for embedded and air-gapped systems where /dev/urandom may not be
+// well seeded early in boot.  It is slow and is best used as one of the sources
+// of an XORSource or with WithChaCha20.
+//
+// A JitterReader is not safe for concurrent use.
+type JitterReader struct {
+	// Samples is the number of timing samples hashed into each 32 bytes
+	// of output.  It defaults to 1024.
+	Samples int
+
+	mem  [4096]byte
+	pos  uint64
+	last int64            // the previous timing delta
+	now  func() time.Time // time.Now if nil, replaced by tests
+}
+
+// Read fills buf with conditioned jitter entropy.  It returns an error wrapping
+// ErrJitterStuck if too many consecutive samples are stuck.
+func (j *JitterReader) Read(buf []byte) (int, error) {
+	samples := j.Samples
+	if samples <= 0 {
+		samples = defaultJitterSamples
+	}
+	var b [8]byte
+	n := 0
+	for n < len(buf) {
+		h := sha256.New()
+		for i, stuck := 0, 0; i < samples; {
+			d := j.sample()
+			// Discard stuck samples, which carry no entropy.
+			if d == j.last {
+				if stuck++; stuck >= maxStuckSamples {
+					return n, fmt.Errorf("%w: %d in a row", ErrJitterStuck, stuck)
+				}
+				continue
+			}
+			stuck = 0
+			j.last = d
+			binary.LittleEndian.PutUint64(b[:], uint64(d))
+			h.Write(b[:])
+			i++
+		}
+		n += copy(buf[n:], h.Sum(nil))
+	}
+	return n, nil
+}
+
+// sample returns the time taken to walk a varying portion of j.mem.
+func (j *JitterReader) sample() int64 {
+	now := j.now
+	if now == nil {
+		now = time.Now
+	}
+	start := now()
+	// The number of steps depends on the previous sample to add
+	// variation of its own.
+	steps := 64 + uint64(j.last)&63
+	for i := uint64(0); i < steps; i++ {
+		j.pos = (j.pos + 67) % uint64(len(j.mem))
+		j.mem[j.pos] += byte(i) + 1
+	}
+	return now().Sub(start).Nanoseconds()
+}
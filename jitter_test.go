@@ -0,0 +1,32 @@
+package cachedrander
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestJitterReader(t *testing.T) {
+	j := &JitterReader{Samples: 64}
+	var a, b [40]byte
+	if _, err := io.ReadFull(j, a[:]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadFull(j, b[:]); err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Errorf("two reads returned the same data: %x", a)
+	}
+}
+
+func TestJitterReaderStuck(t *testing.T) {
+	// A clock that never advances, as one coarser than a sample appears.
+	now := time.Now()
+	j := &JitterReader{Samples: 64, now: func() time.Time { return now }}
+	var buf [32]byte
+	if _, err := j.Read(buf[:]); !errors.Is(err, ErrJitterStuck) {
+		t.Errorf("got error %v, want %v", err, ErrJitterStuck)
+	}
+}
@@ -0,0 +1,49 @@
+package cachedrander
+
+import "context"
+
+// WithDirectKeys causes GenerateKey to read keys directly from the source
+// rather than from the cache.  Keys then never pass through memory shared with
+// other callers, at the cost of a call to the source, made while holding the
+// fill mutex, for every key.  The keys are read from the source passed to New
+// or Reset, not through options such as WithChaCha20, WithCTRDRBG, or
+// WithSHA256Conditioning that wrap it.
+func WithDirectKeys() Option {
+	return func(r *CachedReader) {
+		r.directKeys = true
+	}
+}
+
+// GenerateKey returns an n byte key.
+//
+// Unless WithDirectKeys is used the key is taken from the cache, which has some
+// caveats worth considering for long lived secrets.  The key's bytes were held
+// in the cache, in memory readable by the whole process, until they were served
+// and remain there until the page is reloaded or Close is called.  The key is
+// only as good as the source, and the CachedReader must be reseeded if the
+// process forks or the virtual machine is cloned (see WithForkDetection and
+// WithVMGenerationID).
+func (r *CachedReader) GenerateKey(n int) ([]byte, error) {
+	key := make([]byte, n)
+	if r.directKeys {
+		if _, err := r.readSource(r.src, key); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+	if err := r.readChunks(context.Background(), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Salt returns an n byte salt, such as for a password hash or key derivation
+// function.  Salts need only be unique, not secret, so they are always taken
+// from the cache, even when WithDirectKeys is used.
+func (r *CachedReader) Salt(n int) ([]byte, error) {
+	salt := make([]byte, n)
+	if err := r.readChunks(context.Background(), salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
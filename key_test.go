@@ -0,0 +1,66 @@
+package cachedrander
+
+import "testing"
+
+func TestGenerateKey(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := r.GenerateKey(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != 32 || key[0] != 0 || key[31] != 31 {
+		t.Errorf("GenerateKey got %v", key)
+	}
+	salt, err := r.Salt(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(salt) != 16 || salt[0] != 32 {
+		t.Errorf("Salt got %v", salt)
+	}
+}
+
+func TestDirectKeys(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64, WithDirectKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first page holds 0 through 63, the key is read after it.
+	key, err := r.GenerateKey(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key[0] != 64 || key[31] != 95 {
+		t.Errorf("GenerateKey got %v, want 64 through 95", key)
+	}
+	salt, err := r.Salt(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if salt[0] != 0 {
+		t.Errorf("Salt got %v, want 0 through 15", salt)
+	}
+}
+
+func TestDirectKeysWrapped(t *testing.T) {
+	r, err := New(&gen{size: 17}, 64, WithDirectKeys(), WithChaCha20(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	key, err := r.GenerateKey(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The source, unlike the DRBG, returns consecutive bytes.
+	for i := 1; i < len(key); i++ {
+		if key[i] != key[i-1]+1 {
+			t.Fatalf("GenerateKey got %v, want consecutive bytes from the source", key)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Package ksuidpool generates github.com/segmentio/ksuid KSUIDs whose random
+// payloads come from a cachedrander.CachedReader.  Unlike calling
+// ksuid.SetRand, using a Pool does not change the global state of the ksuid
+// package.
+//
+// There is no equivalent package for github.com/rs/xid.  An xid contains no
+// per-ID random data (it is built from a machine ID, the process ID, and a
+// counter) so there is nothing for a CachedReader to supply.
+package ksuidpool
+
+import (
+	"time"
+
+	"github.com/pborman/cachedrander"
+	"github.com/segmentio/ksuid"
+)
+
+// A Pool generates KSUIDs from the data cached by a CachedReader.
+type Pool struct {
+	r *cachedrander.CachedReader
+}
+
+// New returns a Pool that caches n KSUID payloads' worth of data from
+// crypto/rand at a time.  A KSUID payload is the same size as a UUID.
+func New(n int, opts ...cachedrander.Option) (*Pool, error) {
+	r, err := cachedrander.NewUUIDReader(n, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Pool{r: r}, nil
+}
+
+// NewFromReader returns a Pool that generates KSUIDs from r.
+func NewFromReader(r *cachedrander.CachedReader) *Pool {
+	return &Pool{r: r}
+}
+
+// New returns a new KSUID for the current time.
+func (p *Pool) New() (ksuid.KSUID, error) {
+	return p.NewWithTime(time.Now())
+}
+
+// NewWithTime returns a new KSUID for the time t.
+func (p *Pool) NewWithTime(t time.Time) (ksuid.KSUID, error) {
+	b, err := p.r.Read16()
+	if err != nil {
+		return ksuid.Nil, err
+	}
+	return ksuid.FromParts(t, b[:])
+}
@@ -0,0 +1,51 @@
+package ksuidpool
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+func TestNew(t *testing.T) {
+	p, err := New(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	const workers, count = 8, 1000
+	ids := make([][]ksuid.KSUID, workers)
+	var wg sync.WaitGroup
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < count; j++ {
+				id, err := p.NewWithTime(now)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				ids[i] = append(ids[i], id)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	seen := map[ksuid.KSUID]bool{}
+	for _, list := range ids {
+		for _, id := range list {
+			if seen[id] {
+				t.Fatalf("%v: duplicate KSUID", id)
+			}
+			seen[id] = true
+			if !id.Time().Equal(now.Truncate(time.Second)) {
+				t.Fatalf("%v: got time %v, want %v", id, id.Time(), now.Truncate(time.Second))
+			}
+		}
+	}
+	if len(seen) != workers*count {
+		t.Errorf("got %d KSUIDs, want %d", len(seen), workers*count)
+	}
+}
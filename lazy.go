@@ -0,0 +1,61 @@
+package cachedrander
+
+import "context"
+
+// WithLazyInit causes New to return without reading from the source.  The
+// first page is loaded by the first Read, or ahead of time by Warmup, so
+// programs with strict startup time budgets do not wait for entropy during
+// initialization.  Since New does not read the source, errors from the source
+// are not reported until the first Read.
+func WithLazyInit() Option {
+	return func(r *CachedReader) {
+		r.lazy = true
+	}
+}
+
+// unloadedPage returns the exhausted page that is current before the first
+// page has been loaded.  Its generation is one less than 0 so the next page is
+// generation 0.
+func (r *CachedReader) unloadedPage() *page {
+	p := &page{buf: r.bufs[0], gen: noGen}
+	p.offset.Store(1)
+	p.retired.Store(true)
+	return p
+}
+
+// Warmup loads the current page, if it has not been loaded (see WithLazyInit),
+// and every standby page that is not already loaded, so that subsequent Reads
+// do not wait for the source.  ctx is checked before each page is loaded; a
+// load in progress is not interrupted.  Warmup can be used to gate readiness
+// checks on the cache being full.
+func (r *CachedReader) Warmup(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrClosed
+	}
+	if p := r.cur.Load(); p.offset.Load() > p.size {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.advance(); err != nil {
+			return err
+		}
+	}
+	for {
+		n := r.standby()
+		if n >= len(r.bufs)-1 {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		count := 1
+		if r.workers > 1 {
+			count = len(r.bufs) - 1 - n
+		}
+		if err := r.loadPages(r.cur.Load().gen+uint64(n)+1, count); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package cachedrander
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLazyInit(t *testing.T) {
+	f := &flakyReader{fail: true}
+	r, err := New(f, 64, WithLazyInit())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.reads != 0 {
+		t.Fatalf("New read the source %d times", f.reads)
+	}
+	if s := r.Stats(); s.Fills != 0 || s.BytesServed != 0 {
+		t.Errorf("got %+v, want no fills", s)
+	}
+	var buf [16]byte
+	if _, err := r.Read(buf[:]); err == nil {
+		t.Fatal("Read did not return the source's error")
+	}
+	f.fail = false
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	if buf[0] != 1 {
+		t.Errorf("got %v, want data from the source", buf)
+	}
+	if s := r.Stats(); s.BytesServed != 16 {
+		t.Errorf("BytesServed got %d, want 16", s.BytesServed)
+	}
+}
+
+func TestLazyInitSequential(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64, WithLazyInit(), WithWarmStandby(), WithMax(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	checkSequential(t, r)
+}
+
+func TestWarmup(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64, WithLazyInit(), WithPageCount(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Warmup(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s := r.Stats(); s.Fills != 4 {
+		t.Errorf("got %d fills, want 4", s.Fills)
+	}
+	// All the pages are loaded so Warmup has nothing to do.
+	if err := r.Warmup(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s := r.Stats(); s.Fills != 4 {
+		t.Errorf("got %d fills, want 4", s.Fills)
+	}
+	// The loaded pages are served without waiting for the source.
+	var buf [16]byte
+	for i := 0; i < 4*64/16; i++ {
+		if _, err := r.Read(buf[:]); err != nil {
+			t.Fatal(err)
+		}
+		if buf[0] != byte(i*16) {
+			t.Fatalf("read %d: got data starting at %d, want %d", i, buf[0], byte(i*16))
+		}
+	}
+	if s := r.Stats(); s.BlockedReads != 0 {
+		t.Errorf("got %d blocked reads, want 0", s.BlockedReads)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r, err = New(g, 64, WithLazyInit())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Warmup(ctx); err != context.Canceled {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
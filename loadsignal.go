@@ -0,0 +1,76 @@
+package cachedrander
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// A loadSignal lets Reads that need the next page wait for a page load in
+// progress without acquiring CachedReader.mu.  Only the goroutine loading pages
+// holds the mutex; the others wait on a channel that is closed when the load
+// finishes and then try to swap in the new page, so they are not queued on the
+// mutex behind each other.
+type loadSignal struct {
+	active atomic.Bool
+	done   atomic.Pointer[chan struct{}] // closed when the active load finishes
+}
+
+// start records that a page load is in progress.
+func (s *loadSignal) start() {
+	s.active.Store(true)
+}
+
+// finish records that the page load has finished and wakes up the waiters.
+// active is cleared before done is replaced, so a waiter that sees active set
+// after loading done will be woken.
+func (s *loadSignal) finish() {
+	s.active.Store(false)
+	ch := make(chan struct{})
+	if old := s.done.Swap(&ch); old != nil {
+		close(*old)
+	}
+}
+
+// wait waits for the page load in progress, if any, to finish.  It reports
+// false, without waiting, if no page is being loaded.  It returns ctx.Err() if
+// ctx is done before the load finishes.
+func (s *loadSignal) wait(ctx context.Context) (bool, error) {
+	done := s.done.Load()
+	if done == nil || !s.active.Load() {
+		return false, nil
+	}
+	select {
+	case <-*done:
+		return true, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// An asyncCall is a fill, or a probe by Ping, run on behalf of callers that
+// may stop waiting for it.  At most one of each is in progress per
+// CachedReader, so callers that give up on a hung source do not leave
+// goroutines behind.
+type asyncCall struct {
+	done chan struct{} // closed when the call returns
+	err  error
+}
+
+// runAsync returns the call of f stored in slot, starting one if there is
+// none.
+func runAsync(slot *atomic.Pointer[asyncCall], f func() error) *asyncCall {
+	for {
+		if c := slot.Load(); c != nil {
+			return c
+		}
+		c := &asyncCall{done: make(chan struct{})}
+		if slot.CompareAndSwap(nil, c) {
+			go func() {
+				c.err = f()
+				slot.Store(nil)
+				close(c.done)
+			}()
+			return c
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package cachedrander
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadSignal(t *testing.T) {
+	ctx := context.Background()
+	var s loadSignal
+	if ok, _ := s.wait(ctx); ok {
+		t.Fatal("wait waited before any load")
+	}
+	s.start()
+	s.finish()
+	if ok, _ := s.wait(ctx); ok {
+		t.Fatal("wait waited with no load in progress")
+	}
+	s.start()
+	woke := make(chan bool)
+	go func() {
+		ok, _ := s.wait(ctx)
+		woke <- ok
+	}()
+	select {
+	case <-woke:
+		t.Fatal("wait returned before the load finished")
+	case <-time.After(10 * time.Millisecond):
+	}
+	s.finish()
+	if !<-woke {
+		t.Error("wait reported no load in progress")
+	}
+
+	s.start()
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := s.wait(cctx); err != context.Canceled {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+	s.finish()
+}
+
+func TestWaitForLoad(t *testing.T) {
+	s := &slowReader{release: make(chan struct{}, 1)}
+	s.release <- struct{}{}
+	r, err := New(s, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [16]byte
+	r.Read(buf[:])
+
+	// One reader loads the next page while the rest wait for it.
+	const readers = 8
+	var wg sync.WaitGroup
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var buf [16]byte
+			if _, err := r.Read(buf[:]); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	deadline := time.Now().Add(time.Second)
+	for r.blocked.Load() != readers || !r.loads.active.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("readers did not wait")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// Each page serves one reader.
+	for i := 0; i < readers; i++ {
+		s.release <- struct{}{}
+	}
+	wg.Wait()
+	if s := r.Stats(); s.Fills != readers+1 || s.BytesServed != 16*(readers+1) {
+		t.Errorf("got %d fills and %d bytes served, want %d and %d", s.Fills, s.BytesServed, readers+1, 16*(readers+1))
+	}
+}
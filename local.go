@@ -0,0 +1,105 @@
+package cachedrander
+
+import "context"
+
+// A Local is a small private cache of data taken from a CachedReader.  A Local
+// must only be used by a single goroutine, typically a worker that mints many
+// UUIDs.  Data is taken from the CachedReader one buffer at a time, so the
+// atomic operation is only needed once per buffer rather than once per UUID.
+//
+// Data buffered by a Local is dropped when its CachedReader discards its cached
+// data, such as by Reseed or after a fork is detected.
+type Local struct {
+	r        *CachedReader
+	buf      []byte
+	off      int    // bytes of buf already served
+	discards uint64 // r.discards when buf was filled
+}
+
+// Local returns a new Local that takes n UUIDs' worth (n*16 bytes) of data from
+// r at a time.  Values of n less than 1 are treated as 16.
+func (r *CachedReader) Local(n int) *Local {
+	if n < 1 {
+		n = 16
+	}
+	return r.newLocal(n * 16)
+}
+
+// WithStride causes Read to reserve n bytes of the current page at a time and
+// serve subsequent small reads from the reserved span, so the atomic operation
+// is only needed once per n bytes.  Spans are kept in a sync.Pool, so data
+// reserved by one goroutine may be served to another and unused spans may be
+// wasted when the pool is cleared by the garbage collector.  Values of n less
+// than or equal to 0 disable striding.
+func WithStride(n int) Option {
+	return func(r *CachedReader) {
+		r.stride = max(n, 0)
+	}
+}
+
+// newLocal returns a new Local that takes size bytes from r at a time.
+func (r *CachedReader) newLocal(size int) *Local {
+	buf := make([]byte, size)
+	return &Local{r: r, buf: buf, off: len(buf)}
+}
+
+// Read fills buf with data from l.  Unlike CachedReader.Read, Read always fills
+// all of buf unless an error is returned.
+func (l *Local) Read(buf []byte) (int, error) {
+	return l.read(context.Background(), buf)
+}
+
+// read implements Read, returning early if ctx is done while waiting for the
+// CachedReader to load a page.
+func (l *Local) read(ctx context.Context, buf []byte) (int, error) {
+	if err := l.r.checkFork(); err != nil {
+		return 0, err
+	}
+	if l.r.discards.Load() != l.discards {
+		// Data in l.buf was discarded by l.r.
+		clear(l.buf[l.off:])
+		l.off = len(l.buf)
+	}
+	n := 0
+	for n < len(buf) {
+		if l.off == len(l.buf) {
+			if err := l.fill(ctx); err != nil {
+				return n, err
+			}
+		}
+		c := copy(buf[n:], l.buf[l.off:])
+		clear(l.buf[l.off : l.off+c])
+		l.off += c
+		n += c
+	}
+	return n, nil
+}
+
+// Read16 returns 16 bytes of data from l, the size of a UUID.
+func (l *Local) Read16() ([16]byte, error) {
+	var b [16]byte
+	_, err := l.Read(b[:])
+	return b, err
+}
+
+// fill refills l.buf from l.r.
+func (l *Local) fill(ctx context.Context) error {
+	discards := l.r.discards.Load()
+	if err := l.r.readChunks(ctx, l.buf); err != nil {
+		return err
+	}
+	l.discards = discards
+	l.off = 0
+	return nil
+}
+
+// readStride fills buf from a span of reserved data.
+func (r *CachedReader) readStride(ctx context.Context, buf []byte) (int, error) {
+	l, _ := r.spans.Get().(*Local)
+	if l == nil {
+		l = r.newLocal(r.stride)
+	}
+	n, err := l.read(ctx, buf)
+	r.spans.Put(l)
+	return n, err
+}
@@ -0,0 +1,67 @@
+package cachedrander
+
+import "testing"
+
+func TestLocal(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := r.Local(1)
+	checkSequential(t, l)
+	// checkSequential read 2016 bytes, exactly 126 UUIDs' worth.
+	if s := r.Stats(); s.BytesServed != 2016 {
+		t.Errorf("BytesServed got %d, want 2016", s.BytesServed)
+	}
+}
+
+func TestLocalReseed(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := r.Local(2)
+	if _, err := l.Read16(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reseed(); err != nil {
+		t.Fatal(err)
+	}
+	// The second UUID in l was discarded and the next page starts at 64.
+	b, err := l.Read16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b[0] != 64 {
+		t.Errorf("got data starting at %d, want 64", b[0])
+	}
+}
+
+func TestStride(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 1024, WithStride(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [16]byte
+	for i := 0; i < 20; i++ {
+		n, err := r.Read(buf[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != len(buf) {
+			t.Fatalf("read %d: got %d bytes, want %d", i, n, len(buf))
+		}
+		for j := 1; j < n; j++ {
+			if buf[j] != buf[0]+byte(j) {
+				t.Fatalf("read %d: data not from a single span: %v", i, buf)
+			}
+		}
+		// Data is only reserved in whole spans.
+		if off := r.cur.Load().offset.Load(); off%64 != 0 {
+			t.Fatalf("read %d: offset %d is not a multiple of the stride", i, off)
+		}
+	}
+}
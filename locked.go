@@ -0,0 +1,12 @@
+package cachedrander
+
+// WithLockedPages causes pages to be allocated outside of the Go heap, locked
+// into RAM with mlock(2) so they are never written to swap, and excluded from
+// core dumps with MADV_DONTDUMP.  It is shorthand for WithAllocator with an
+// Allocator that does so.  Locked memory is limited by RLIMIT_MEMLOCK, so New
+// returns an error if the pages cannot be locked.  WithLockedPages is only
+// supported on Linux; on other platforms New returns an error wrapping
+// errors.ErrUnsupported.
+func WithLockedPages() Option {
+	return WithAllocator(lockedAllocator{})
+}
@@ -0,0 +1,37 @@
+package cachedrander
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// A lockedAllocator allocates pages for WithLockedPages.
+type lockedAllocator struct{}
+
+// Alloc returns size bytes of anonymous memory that is locked into RAM and
+// excluded from core dumps.
+func (lockedAllocator) Alloc(size int) ([]byte, error) {
+	b, err := unix.Mmap(-1, 0, size, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_PRIVATE|unix.MAP_ANONYMOUS)
+	if err != nil {
+		return nil, fmt.Errorf("cachedrander: mmap: %w", err)
+	}
+	if err := unix.Mlock(b); err != nil {
+		unix.Munmap(b)
+		return nil, fmt.Errorf("cachedrander: mlock: %w", err)
+	}
+	if err := unix.Madvise(b, unix.MADV_DONTDUMP); err != nil {
+		unix.Munmap(b)
+		return nil, fmt.Errorf("cachedrander: madvise: %w", err)
+	}
+	return b, nil
+}
+
+// Free zeros and unmaps b, which also unlocks it.
+func (lockedAllocator) Free(b []byte) error {
+	clear(b)
+	if err := unix.Munmap(b); err != nil {
+		return fmt.Errorf("cachedrander: munmap: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package cachedrander
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+// vmFlags returns the VmFlags of the mapping containing b from
+// /proc/self/smaps.
+func vmFlags(t *testing.T, b []byte) []string {
+	t.Helper()
+	f, err := os.Open("/proc/self/smaps")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer f.Close()
+	addr := uintptr(unsafe.Pointer(&b[0]))
+	found := false
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := s.Text()
+		var start, end uintptr
+		if n, _ := fmt.Sscanf(line, "%x-%x", &start, &end); n == 2 {
+			found = start <= addr && addr < end
+			continue
+		}
+		if found && strings.HasPrefix(line, "VmFlags:") {
+			return strings.Fields(line)[1:]
+		}
+	}
+	t.Fatal("mapping not found")
+	return nil
+}
+
+func TestLockedPages(t *testing.T) {
+	r, err := New(&gen{size: 17}, 4096, WithLockedPages())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Max = 8
+	checkSequential(t, r)
+
+	// lo is locked and dd is excluded from core dumps.
+	flags := vmFlags(t, r.bufs[0].data)
+	for _, flag := range []string{"lo", "dd"} {
+		if !slices.Contains(flags, flag) {
+			t.Errorf("page mapping flags %v do not include %q", flags, flag)
+		}
+	}
+
+	r.Close()
+	var buf [8]byte
+	if _, err := r.Read(buf[:]); err != ErrClosed {
+		t.Errorf("got error %v, want %v", err, ErrClosed)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+
+package cachedrander
+
+import (
+	"errors"
+	"fmt"
+)
+
+// A lockedAllocator allocates pages for WithLockedPages, which is only
+// supported on Linux.
+type lockedAllocator struct{}
+
+// Alloc returns an error wrapping errors.ErrUnsupported.
+func (lockedAllocator) Alloc(size int) ([]byte, error) {
+	return nil, fmt.Errorf("cachedrander: locked pages: %w", errors.ErrUnsupported)
+}
+
+// Free does nothing.
+func (lockedAllocator) Free(b []byte) error { return nil }
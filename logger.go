@@ -0,0 +1,42 @@
+package cachedrander
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// defaultSlowFill is how long a page load may take before it is logged as
+// slow, unless changed by WithSlowFillThreshold.
+const defaultSlowFill = 100 * time.Millisecond
+
+// WithLogger causes the CachedReader to log its activity to l: page loads at
+// the debug level, discarded data (such as by Reseed) at the info level, and
+// slow or failed page loads, reads served by WithFallback, and changes to the
+// state of the circuit breaker at the warning level.  Reads served from the
+// cache are never logged.
+func WithLogger(l *slog.Logger) Option {
+	return func(r *CachedReader) {
+		r.logger = l
+	}
+}
+
+// log logs msg at level if a logger was provided with WithLogger.
+func (r *CachedReader) log(level slog.Level, msg string, args ...any) {
+	if r.logger != nil {
+		r.logger.Log(context.Background(), level, msg, args...)
+	}
+}
+
+// logFill logs the loading of a page that took d and returned err.
+func (r *CachedReader) logFill(gen uint64, d time.Duration, err error) {
+	switch {
+	case r.logger == nil:
+	case err != nil:
+		r.log(slog.LevelWarn, "cachedrander: page load failed", "gen", gen, "duration", d, "error", err)
+	case d >= r.slowFill:
+		r.log(slog.LevelWarn, "cachedrander: slow page load", "gen", gen, "duration", d)
+	default:
+		r.log(slog.LevelDebug, "cachedrander: page loaded", "gen", gen, "duration", d)
+	}
+}
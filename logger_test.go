@@ -0,0 +1,43 @@
+package cachedrander
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	f := &flakyReader{}
+	r, err := New(f, 16, WithLogger(l), WithCircuitBreaker(1, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reseed(); err != nil {
+		t.Fatal(err)
+	}
+	f.fail = true
+	var b [32]byte
+	r.Read(b[:])
+	r.Read(b[:])
+	r.Close()
+	out := buf.String()
+	for _, want := range []string{
+		"level=DEBUG msg=\"cachedrander: page loaded\" gen=0",
+		"level=INFO msg=\"cachedrander: cached data discarded\"",
+		"level=WARN msg=\"cachedrander: page load failed\" gen=2",
+		"level=WARN msg=\"cachedrander: circuit breaker opened\"",
+		"level=WARN msg=\"cachedrander: read served from crypto/rand\"",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log does not contain %q:\n%s", want, out)
+		}
+	}
+	// Close does not log the discarded data.
+	if n := strings.Count(out, "discarded"); n != 1 {
+		t.Errorf("got %d discards logged, want 1", n)
+	}
+}
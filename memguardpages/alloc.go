@@ -0,0 +1,62 @@
+// Package memguardpages provides a cachedrander.Allocator that stores pages in
+// memory managed by github.com/awnumar/memguard.  Pages are locked into RAM so
+// they are never written to swap, are surrounded by guard pages that fault on
+// overflows, and are wiped when freed.  It is intended for high-assurance
+// deployments where cached random data must not leak from the process:
+//
+//	alloc := memguardpages.New()
+//	r, err := cachedrander.NewUUIDReader(1000, cachedrander.WithAllocator(alloc))
+package memguardpages
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/awnumar/memguard"
+)
+
+// ErrAllocFailed is returned by Alloc when memguard cannot allocate a page.
+var ErrAllocFailed = errors.New("memguardpages: allocation failed")
+
+// An Allocator is a cachedrander.Allocator that allocates pages with
+// memguard.  The zero value is not usable; use New.
+type Allocator struct {
+	mu   sync.Mutex
+	bufs map[*byte]*memguard.LockedBuffer
+}
+
+// New returns a new Allocator.
+func New() *Allocator {
+	return &Allocator{bufs: map[*byte]*memguard.LockedBuffer{}}
+}
+
+// Alloc returns size bytes of locked memory.
+func (a *Allocator) Alloc(size int) ([]byte, error) {
+	if size < 1 {
+		return nil, ErrAllocFailed
+	}
+	lb := memguard.NewBuffer(size)
+	if !lb.IsAlive() {
+		return nil, ErrAllocFailed
+	}
+	b := lb.Bytes()
+	a.mu.Lock()
+	a.bufs[&b[0]] = lb
+	a.mu.Unlock()
+	return b, nil
+}
+
+// Free wipes and releases memory returned by Alloc.
+func (a *Allocator) Free(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	a.mu.Lock()
+	lb := a.bufs[&b[0]]
+	delete(a.bufs, &b[0])
+	a.mu.Unlock()
+	if lb != nil {
+		lb.Destroy()
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package memguardpages
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/pborman/cachedrander"
+)
+
+func TestAllocator(t *testing.T) {
+	a := New()
+	b, err := a.Alloc(4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 4096 {
+		t.Fatalf("got %d bytes, want 4096", len(b))
+	}
+	b[0], b[4095] = 1, 2
+	if err := a.Free(b); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.bufs) != 0 {
+		t.Errorf("got %d buffers after Free, want 0", len(a.bufs))
+	}
+	if _, err := a.Alloc(0); err != ErrAllocFailed {
+		t.Errorf("got error %v, want %v", err, ErrAllocFailed)
+	}
+}
+
+func TestCachedReader(t *testing.T) {
+	a := New()
+	r, err := cachedrander.New(rand.Reader, 1600, cachedrander.WithAllocator(a))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if _, err := r.Read16(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(a.bufs) != 2 {
+		t.Errorf("got %d buffers, want 2", len(a.bufs))
+	}
+	r.Close()
+	if len(a.bufs) != 0 {
+		t.Errorf("got %d buffers after Close, want 0", len(a.bufs))
+	}
+}
@@ -0,0 +1,37 @@
+package cachedrander
+
+import "time"
+
+// A Metrics receives measurements from a CachedReader as they are made.  It
+// allows a CachedReader to feed a metrics system, such as Prometheus, without
+// this package depending on it.  The methods of a Metrics are called on the
+// paths that load pages, never for Reads served from the cache, and may be
+// called concurrently.
+type Metrics interface {
+	// Fill is called each time a page has been loaded from the source.
+	// d is how long the load took and err is the error returned by the
+	// source, if any.
+	Fill(d time.Duration, err error)
+
+	// Blocked is called each time a Read found the current page exhausted
+	// and had to wait d for the next page to become available.
+	Blocked(d time.Duration)
+}
+
+// WithErrorHandler causes f to be called with every error returned by the
+// source, including errors that are subsequently retried (see WithRetry) or
+// hidden from Read (see WithFallback).  This lets the application log or alert
+// on a failing source.  f is called while the fill mutex is held, so it must
+// not call methods of the CachedReader, and should return quickly.
+func WithErrorHandler(f func(error)) Option {
+	return func(r *CachedReader) {
+		r.onError = f
+	}
+}
+
+// WithMetrics causes the CachedReader to report its measurements to m.
+func WithMetrics(m Metrics) Option {
+	return func(r *CachedReader) {
+		r.metrics = m
+	}
+}
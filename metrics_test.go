@@ -0,0 +1,75 @@
+package cachedrander
+
+import (
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testMetrics struct {
+	mu      sync.Mutex
+	fills   int
+	errs    int
+	blocked int
+}
+
+func (m *testMetrics) Fill(d time.Duration, err error) {
+	m.mu.Lock()
+	m.fills++
+	if err != nil {
+		m.errs++
+	}
+	m.mu.Unlock()
+}
+
+func (m *testMetrics) Blocked(d time.Duration) {
+	m.mu.Lock()
+	m.blocked++
+	m.mu.Unlock()
+}
+
+func TestMetrics(t *testing.T) {
+	m := &testMetrics{}
+	r, err := New(&gen{size: 17}, 64, WithMetrics(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [16]byte
+	for i := 0; i < 9; i++ {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if m.fills != 3 || m.errs != 0 || m.blocked != 2 {
+		t.Errorf("got %d fills, %d errors, %d blocked; want 3, 0, 2", m.fills, m.errs, m.blocked)
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	f := &failingReader{failures: 1, r: &gen{size: 17}}
+	var errs []error
+	r, err := New(f, 16, WithRetry(1, time.Millisecond), WithFallback(),
+		WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first failure was retried.
+	if len(errs) != 1 {
+		t.Fatalf("got %d errors, want 1", len(errs))
+	}
+	f.failures = 2
+	var buf [16]byte
+	for i := 0; i < 2; i++ {
+		if _, err := r.Read(buf[:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The second Read fell back to crypto/rand after two failures.
+	if len(errs) != 3 {
+		t.Errorf("got %d errors, want 3", len(errs))
+	}
+	if s := r.Stats(); s.Fallbacks != 1 {
+		t.Errorf("got %d fallbacks, want 1", s.Fallbacks)
+	}
+}
@@ -0,0 +1,122 @@
+package cachedrander
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrSourceTimeout is returned by a MultiSource when a source did not respond
+// within its Timeout.
+var ErrSourceTimeout = errors.New("cachedrander: source timed out")
+
+// defaultRetryAfter is the default MultiSource RetryAfter.
+const defaultRetryAfter = 30 * time.Second
+
+// A MultiSource is a source that reads from a primary source and fails over to
+// secondary sources when it fails.  A source that returns an error, or does not
+// respond within Timeout, is marked unhealthy and is not tried again until
+// RetryAfter has passed, at which point it is probed by the next Read.  Sources
+// are always tried in order, healthy sources first, so the primary source is
+// used again as soon as it recovers.
+//
+// A MultiSource is safe for concurrent use but only reads from one source at a
+// time.
+type MultiSource struct {
+	// Timeout, if not zero, is how long to wait for a source to respond.
+	// A source that times out continues to be read in the background and
+	// the data it eventually returns is discarded.
+	Timeout time.Duration
+
+	// RetryAfter is how long an unhealthy source is skipped.  It
+	// defaults to 30 seconds.
+	RetryAfter time.Duration
+
+	mu      sync.Mutex
+	sources []io.Reader
+	failed  []time.Time // when each source last failed, zero if healthy
+}
+
+// NewMultiSource returns a MultiSource that reads from primary, failing over
+// to the secondaries in order.
+func NewMultiSource(primary io.Reader, secondaries ...io.Reader) *MultiSource {
+	sources := append([]io.Reader{primary}, secondaries...)
+	return &MultiSource{
+		sources: sources,
+		failed:  make([]time.Time, len(sources)),
+	}
+}
+
+// Read reads from the first healthy source that succeeds.  An error is only
+// returned if every source fails.
+func (m *MultiSource) Read(buf []byte) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	retryAfter := m.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = defaultRetryAfter
+	}
+	now := time.Now()
+	var healthy, unhealthy []int
+	for i, t := range m.failed {
+		if t.IsZero() || now.Sub(t) >= retryAfter {
+			healthy = append(healthy, i)
+		} else {
+			unhealthy = append(unhealthy, i)
+		}
+	}
+	var err error
+	for _, i := range append(healthy, unhealthy...) {
+		var n int
+		n, err = m.read(m.sources[i], buf)
+		if err == nil {
+			m.failed[i] = time.Time{}
+		} else {
+			m.failed[i] = time.Now()
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+	return 0, err
+}
+
+// read reads from src, giving up after m.Timeout.
+func (m *MultiSource) read(src io.Reader, buf []byte) (int, error) {
+	if m.Timeout <= 0 {
+		return src.Read(buf)
+	}
+	type result struct {
+		n   int
+		err error
+	}
+	// The read may outlive this call so it must have its own buffer.
+	tmp := make([]byte, len(buf))
+	c := make(chan result, 1)
+	go func() {
+		n, err := src.Read(tmp)
+		c <- result{n, err}
+	}()
+	t := time.NewTimer(m.Timeout)
+	defer t.Stop()
+	select {
+	case res := <-c:
+		copy(buf, tmp[:res.n])
+		return res.n, res.err
+	case <-t.C:
+		return 0, ErrSourceTimeout
+	}
+}
+
+// Healthy reports which of m's sources are currently considered healthy,
+// primary first.
+func (m *MultiSource) Healthy() []bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h := make([]bool, len(m.failed))
+	for i, t := range m.failed {
+		h[i] = t.IsZero()
+	}
+	return h
+}
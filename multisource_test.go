@@ -0,0 +1,69 @@
+package cachedrander
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// A flakyReader fails while fail is set.
+type flakyReader struct {
+	fail  bool
+	reads int
+}
+
+func (f *flakyReader) Read(buf []byte) (int, error) {
+	f.reads++
+	if f.fail {
+		return 0, errors.New("flaky")
+	}
+	for i := range buf {
+		buf[i] = 1
+	}
+	return len(buf), nil
+}
+
+func TestMultiSource(t *testing.T) {
+	primary := &flakyReader{fail: true}
+	secondary := &flakyReader{}
+	m := NewMultiSource(primary, secondary)
+	m.RetryAfter = time.Hour
+	var buf [8]byte
+	if _, err := io.ReadFull(m, buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	if h := m.Healthy(); h[0] || !h[1] {
+		t.Fatalf("got health %v, want [false true]", h)
+	}
+	// The primary is not retried until RetryAfter has passed.
+	primary.fail = false
+	io.ReadFull(m, buf[:])
+	if primary.reads != 1 {
+		t.Fatalf("primary read %d times, want 1", primary.reads)
+	}
+	m.RetryAfter = time.Nanosecond
+	io.ReadFull(m, buf[:])
+	if h := m.Healthy(); !h[0] {
+		t.Fatal("primary did not recover")
+	}
+
+	primary.fail, secondary.fail = true, true
+	if _, err := m.Read(buf[:]); err == nil {
+		t.Fatal("no error when all sources failed")
+	}
+}
+
+func TestMultiSourceTimeout(t *testing.T) {
+	s := &slowReader{release: make(chan struct{})}
+	defer close(s.release)
+	m := NewMultiSource(s, &flakyReader{})
+	m.Timeout = 10 * time.Millisecond
+	var buf [8]byte
+	if _, err := io.ReadFull(m, buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	if buf[0] != 1 {
+		t.Error("data did not come from the secondary")
+	}
+}
@@ -0,0 +1,25 @@
+package cachedrander
+
+import "io"
+
+// MustNew is like New but panics if New returns an error.  It simplifies
+// initializing a CachedReader in main or a package level variable, where
+// there is no better way to handle the error.
+func MustNew(r io.Reader, size int, opts ...Option) *CachedReader {
+	return must(New(r, size, opts...))
+}
+
+// MustUUIDReader is like NewUUIDReader but panics if NewUUIDReader returns an
+// error.  Since Go 1.24 crypto/rand.Reader does not fail, so MustUUIDReader
+// only panics if an option is invalid or cannot be applied.
+func MustUUIDReader(n int, opts ...Option) *CachedReader {
+	return must(NewUUIDReader(n, opts...))
+}
+
+// must returns r, panicking if err is not nil.
+func must(r *CachedReader, err error) *CachedReader {
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
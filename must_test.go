@@ -0,0 +1,20 @@
+package cachedrander
+
+import "testing"
+
+func TestMust(t *testing.T) {
+	r := MustUUIDReader(10)
+	if _, err := r.Read16(); err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	r = MustNew(&gen{size: 16}, 16)
+	r.Close()
+
+	defer func() {
+		if _, ok := recover().(error); !ok {
+			t.Errorf("MustNew did not panic with an error")
+		}
+	}()
+	MustNew(FailingRander{}, 16)
+}
@@ -0,0 +1,70 @@
+package cachedrander
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Next returns the next n bytes of the current page without copying them.  The
+// returned slice aliases the page and is only valid until release is called.
+// The page cannot be reloaded, and so Reads that need the next page and calls
+// to Close and Reseed will block, until release is called, so release should
+// be called as soon as the bytes are no longer needed.  The slice must not be
+// modified.
+//
+// If the n bytes would straddle the end of the current page the rest of the
+// page is skipped.  An error wrapping ErrInvalidSize is returned if n is
+// larger than a page.
+func (r *CachedReader) Next(n int) (b []byte, release func(), err error) {
+	if n < 0 || uint64(n) > r.size.Load() {
+		return nil, nil, fmt.Errorf("%w: %d is larger than a page", ErrInvalidSize, n)
+	}
+	if err := r.checkFork(); err != nil {
+		return nil, nil, err
+	}
+	blen := uint64(n)
+	for {
+		p := r.cur.Load()
+		end := p.offset.Add(blen)
+		start := end - blen
+		if end <= p.size {
+			// Pin the buffer before checking the stamp so the buffer
+			// cannot be reloaded while it is pinned.
+			p.buf.pins.Add(1)
+			if p.buf.stamp.Load() != p.gen {
+				// The page was reloaded out from under us.
+				p.buf.pins.Add(-1)
+				r.skipped.Add(blen)
+				continue
+			}
+			r.checkWatermark(p, start, end)
+			if p.key != nil {
+				b := make([]byte, n)
+				copy(b, p.data[start:end])
+				p.buf.pins.Add(-1)
+				p.key.decrypt(b, start)
+				r.record(b)
+				return b, func() { clear(b) }, nil
+			}
+			b := p.data[start:end:end]
+			r.record(b)
+			var once sync.Once
+			return b, func() {
+				once.Do(func() {
+					if r.zeroize {
+						clear(b)
+					}
+					p.buf.pins.Add(-1)
+				})
+			}, nil
+		}
+		if start < p.size {
+			// Skip the tail of the page.
+			r.skipped.Add(p.size - start)
+		}
+		if err := r.waitContext(context.Background(), p); err != nil {
+			return nil, nil, err
+		}
+	}
+}
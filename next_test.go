@@ -0,0 +1,65 @@
+package cachedrander
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 40, WithMax(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The third block straddles the end of the first page, so it is taken
+	// from the start of the second page.
+	for i, want := range []byte{0, 16, 40, 56} {
+		b, release, err := r.Next(16)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(b) != 16 || b[0] != want || b[15] != want+15 {
+			t.Errorf("block %d: got %v, want bytes starting at %d", i, b, want)
+		}
+		release()
+		release()
+	}
+	if s := r.Stats(); s.BytesServed != 64 {
+		t.Errorf("BytesServed got %d, want 64", s.BytesServed)
+	}
+	if _, _, err := r.Next(41); !errors.Is(err, ErrInvalidSize) {
+		t.Errorf("got error %v, want %v", err, ErrInvalidSize)
+	}
+}
+
+func TestNextPinned(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 16, WithMax(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, release, err := r.Next(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Reading the third page would reuse the pinned buffer.
+	if _, _, err := r.Next(16); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Next(16)
+	}()
+	select {
+	case <-done:
+		t.Fatal("buffer was reloaded while pinned")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if b[0] != 0 || b[15] != 15 {
+		t.Errorf("pinned data changed: %v", b)
+	}
+	release()
+	<-done
+}
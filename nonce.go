@@ -0,0 +1,66 @@
+package cachedrander
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrDuplicateNonce is returned by Nonce12 and Nonce24 when nonce tracking is
+// enabled and a nonce has been returned before.
+var ErrDuplicateNonce = errors.New("cachedrander: duplicate nonce")
+
+// nonceTracker records every nonce returned when WithNonceTracking is used.
+type nonceTracker struct {
+	mu   sync.Mutex
+	seen map[[24]byte]struct{}
+}
+
+// WithNonceTracking records every nonce returned by Nonce12 and Nonce24 and
+// returns ErrDuplicateNonce if a nonce is ever repeated.  The memory used grows
+// with every nonce, so WithNonceTracking is only intended for debugging and
+// testing.
+func WithNonceTracking() Option {
+	return func(r *CachedReader) {
+		r.nonces = &nonceTracker{seen: map[[24]byte]struct{}{}}
+	}
+}
+
+// Nonce12 returns a 12 byte nonce, the size used by AES-GCM and ChaCha20-Poly1305.
+func (r *CachedReader) Nonce12() ([12]byte, error) {
+	var n [12]byte
+	if err := r.readFull(context.Background(), n[:]); err != nil {
+		return n, err
+	}
+	return n, r.nonces.check(n[:])
+}
+
+// Nonce24 returns a 24 byte nonce, the size used by XChaCha20-Poly1305 and
+// NaCl secretbox.
+func (r *CachedReader) Nonce24() ([24]byte, error) {
+	var n [24]byte
+	if err := r.readFull(context.Background(), n[:]); err != nil {
+		return n, err
+	}
+	return n, r.nonces.check(n[:])
+}
+
+// check returns ErrDuplicateNonce if nonce has been seen before.  Nonces of
+// different lengths are tracked separately.  A nil nonceTracker tracks nothing.
+func (t *nonceTracker) check(nonce []byte) error {
+	if t == nil {
+		return nil
+	}
+	var key [24]byte
+	copy(key[:], nonce)
+	if len(nonce) < len(key) {
+		key[len(key)-1] = byte(len(nonce))
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.seen[key]; ok {
+		return ErrDuplicateNonce
+	}
+	t.seen[key] = struct{}{}
+	return nil
+}
@@ -0,0 +1,51 @@
+package cachedrander
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNonce(t *testing.T) {
+	r, err := New(&gen{size: 17}, 96)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n12, err := r.Nonce12()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n12 != [12]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11} {
+		t.Errorf("Nonce12 got %v", n12)
+	}
+	n24, err := r.Nonce24()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n24[0] != 12 || n24[23] != 35 {
+		t.Errorf("Nonce24 got %v", n24)
+	}
+}
+
+func TestNonceTracking(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		nonce func(*CachedReader) error
+		dup   int // gen repeats every 768 bytes
+	}{
+		{"Nonce12", func(r *CachedReader) error { _, err := r.Nonce12(); return err }, 64},
+		{"Nonce24", func(r *CachedReader) error { _, err := r.Nonce24(); return err }, 32},
+	} {
+		r, err := New(&gen{size: 17}, 96, WithNonceTracking())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < tt.dup; i++ {
+			if err := tt.nonce(r); err != nil {
+				t.Fatalf("%s %d: %v", tt.name, i, err)
+			}
+		}
+		if err := tt.nonce(r); !errors.Is(err, ErrDuplicateNonce) {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, ErrDuplicateNonce)
+		}
+	}
+}
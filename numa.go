@@ -0,0 +1,59 @@
+package cachedrander
+
+import (
+	"io"
+	"math/rand/v2"
+)
+
+// A NUMAPolicy places the shards of a ShardedReader on NUMA nodes.  SystemNUMA
+// returns the policy for the running system.
+type NUMAPolicy interface {
+	// Nodes returns the number of NUMA nodes.  Nodes are numbered from 0.
+	Nodes() int
+
+	// CurrentNode returns the node of the CPU the calling goroutine is
+	// running on.  It is called on every Read so it must be fast.
+	CurrentNode() int
+
+	// Allocator returns an Allocator that allocates memory on node.
+	Allocator(node int) Allocator
+}
+
+// NewShardedNUMA is like NewSharded but creates perNode shards for each NUMA
+// node described by policy.  The pages of each node's shards are allocated on
+// that node and each Read is served by a shard on the node of the CPU it runs
+// on, so on very large machines Reads do not touch memory on other nodes.
+// Values of perNode less than 1 are treated as 1.  Options that set an
+// Allocator, such as WithLockedPages, are overridden.
+func NewShardedNUMA(r io.Reader, size, perNode int, policy NUMAPolicy, opts ...Option) (*ShardedReader, error) {
+	perNode = max(perNode, 1)
+	nodes := max(policy.Nodes(), 1)
+	r = &lockedReader{r: r}
+	sr := &ShardedReader{
+		shards:  make([]*CachedReader, nodes*perNode),
+		numa:    policy,
+		perNode: perNode,
+	}
+	for i := range sr.shards {
+		nodeOpts := append(opts[:len(opts):len(opts)], WithAllocator(policy.Allocator(i/perNode)))
+		cr, err := New(r, size, nodeOpts...)
+		if err != nil {
+			sr.Close()
+			return nil, err
+		}
+		sr.shards[i] = cr
+	}
+	return sr, nil
+}
+
+// shard returns the shard that should serve a Read.
+func (s *ShardedReader) shard() *CachedReader {
+	if s.numa == nil {
+		return s.shards[rand.IntN(len(s.shards))]
+	}
+	node := s.numa.CurrentNode()
+	if node < 0 || node >= len(s.shards)/s.perNode {
+		node = rand.IntN(len(s.shards) / s.perNode)
+	}
+	return s.shards[node*s.perNode+rand.IntN(s.perNode)]
+}
@@ -0,0 +1,103 @@
+package cachedrander
+
+import (
+	"fmt"
+	"math/bits"
+	"os"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// mpolPreferred is the MPOL_PREFERRED memory policy for mbind(2).
+const mpolPreferred = 1
+
+// SystemNUMA returns the NUMA policy for the running system, as described by
+// /sys/devices/system/node.  Its allocators map memory with a preference for
+// their node and CurrentNode uses getcpu(2).  SystemNUMA is only supported on
+// Linux.
+func SystemNUMA() (NUMAPolicy, error) {
+	online, err := os.ReadFile("/sys/devices/system/node/online")
+	if err != nil {
+		return nil, fmt.Errorf("cachedrander: reading NUMA nodes: %w", err)
+	}
+	nodes, err := parseNodeList(strings.TrimSpace(string(online)))
+	if err != nil {
+		return nil, fmt.Errorf("cachedrander: parsing NUMA nodes %q: %w", online, err)
+	}
+	return linuxNUMA{nodes: nodes}, nil
+}
+
+// parseNodeList returns one more than the highest node in list, a list of nodes
+// and ranges of nodes such as "0-3,5".
+func parseNodeList(list string) (int, error) {
+	nodes := 0
+	for _, r := range strings.Split(list, ",") {
+		_, hi, _ := strings.Cut(r, "-")
+		if hi == "" {
+			hi = r
+		}
+		n, err := strconv.Atoi(hi)
+		if err != nil {
+			return 0, err
+		}
+		nodes = max(nodes, n+1)
+	}
+	return nodes, nil
+}
+
+// A linuxNUMA is the NUMAPolicy returned by SystemNUMA.
+type linuxNUMA struct {
+	nodes int
+}
+
+func (l linuxNUMA) Nodes() int { return l.nodes }
+
+// CurrentNode returns the node reported by getcpu(2), or -1 on error.
+func (linuxNUMA) CurrentNode() int {
+	var cpu, node uint32
+	_, _, errno := unix.RawSyscall(unix.SYS_GETCPU, uintptr(unsafe.Pointer(&cpu)), uintptr(unsafe.Pointer(&node)), 0)
+	if errno != 0 {
+		return -1
+	}
+	return int(node)
+}
+
+func (linuxNUMA) Allocator(node int) Allocator {
+	return nodeAllocator{node: node}
+}
+
+// A nodeAllocator allocates memory on a NUMA node.
+type nodeAllocator struct {
+	node int
+}
+
+// Alloc maps size bytes of anonymous memory and sets its policy to prefer
+// a.node.  The policy takes effect as the memory is first touched.
+func (a nodeAllocator) Alloc(size int) ([]byte, error) {
+	b, err := unix.Mmap(-1, 0, size, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_PRIVATE|unix.MAP_ANONYMOUS)
+	if err != nil {
+		return nil, fmt.Errorf("cachedrander: mmap: %w", err)
+	}
+	// The node mask is an array of unsigned longs.
+	mask := make([]uint, a.node/bits.UintSize+1)
+	mask[a.node/bits.UintSize] = 1 << (a.node % bits.UintSize)
+	_, _, errno := unix.Syscall6(unix.SYS_MBIND, uintptr(unsafe.Pointer(&b[0])), uintptr(size),
+		mpolPreferred, uintptr(unsafe.Pointer(&mask[0])), uintptr(len(mask)*bits.UintSize+1), 0)
+	if errno != 0 {
+		unix.Munmap(b)
+		return nil, fmt.Errorf("cachedrander: mbind: %w", errno)
+	}
+	return b, nil
+}
+
+// Free zeros and unmaps b.
+func (nodeAllocator) Free(b []byte) error {
+	clear(b)
+	if err := unix.Munmap(b); err != nil {
+		return fmt.Errorf("cachedrander: munmap: %w", err)
+	}
+	return nil
+}
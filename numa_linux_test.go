@@ -0,0 +1,39 @@
+package cachedrander
+
+import "testing"
+
+func TestParseNodeList(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want int
+	}{
+		{"0", 1},
+		{"0-1", 2},
+		{"0-3,5", 6},
+		{"0,2-3", 4},
+	} {
+		got, err := parseNodeList(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseNodeList(%q) got %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := parseNodeList("x"); err == nil {
+		t.Error("parseNodeList(\"x\") did not return an error")
+	}
+}
+
+func TestSystemNUMA(t *testing.T) {
+	policy, err := SystemNUMA()
+	if err != nil {
+		t.Skip(err)
+	}
+	if n := policy.CurrentNode(); n < 0 || n >= policy.Nodes() {
+		t.Errorf("current node %d is not less than %d", n, policy.Nodes())
+	}
+	r, err := NewShardedNUMA(&gen{size: 17}, 4096, 2, policy, WithMax(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	checkSequential(t, r.shards[0])
+}
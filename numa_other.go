@@ -0,0 +1,15 @@
+//go:build !linux
+
+package cachedrander
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SystemNUMA returns the NUMA policy for the running system.  It is only
+// supported on Linux; on other platforms it returns an error wrapping
+// errors.ErrUnsupported.
+func SystemNUMA() (NUMAPolicy, error) {
+	return nil, fmt.Errorf("cachedrander: NUMA: %w", errors.ErrUnsupported)
+}
@@ -0,0 +1,72 @@
+package cachedrander
+
+import "testing"
+
+// testNUMA is a NUMAPolicy with a settable current node.
+type testNUMA struct {
+	nodes   int
+	current int
+	allocs  []*testAllocator
+}
+
+func (n *testNUMA) Nodes() int       { return n.nodes }
+func (n *testNUMA) CurrentNode() int { return n.current }
+
+func (n *testNUMA) Allocator(node int) Allocator {
+	for len(n.allocs) <= node {
+		n.allocs = append(n.allocs, &testAllocator{live: map[*byte]int{}})
+	}
+	return n.allocs[node]
+}
+
+func TestShardedNUMA(t *testing.T) {
+	policy := &testNUMA{nodes: 2}
+	r, err := NewShardedNUMA(&gen{size: 256}, 64, 2, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.shards) != 4 {
+		t.Fatalf("got %d shards, want 4", len(r.shards))
+	}
+	for node, a := range policy.allocs {
+		// Each of the node's 2 shards has 2 pages.
+		if len(a.live) != 4 {
+			t.Errorf("node %d has %d pages, want 4", node, len(a.live))
+		}
+	}
+
+	var buf [16]byte
+	for _, node := range []int{0, 1, 5} {
+		policy.current = node
+		for i := 0; i < 100; i++ {
+			r.Read(buf[:])
+		}
+	}
+	for i, s := range r.shards {
+		// Node 5 does not exist so its reads are spread over every
+		// shard.
+		if got := s.Stats().BytesServed; got == 0 {
+			t.Errorf("shard %d was never used", i)
+		}
+	}
+	policy.current = 1
+	var before [4]uint64
+	for i, s := range r.shards {
+		before[i] = s.Stats().BytesServed
+	}
+	for i := 0; i < 100; i++ {
+		r.Read(buf[:])
+	}
+	for i, s := range r.shards[:2] {
+		if got := s.Stats().BytesServed; got != before[i] {
+			t.Errorf("shard %d on node 0 served a read from node 1", i)
+		}
+	}
+
+	r.Close()
+	for node, a := range policy.allocs {
+		if len(a.live) != 0 {
+			t.Errorf("node %d has %d pages after Close", node, len(a.live))
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package cachedrander
+
+import (
+	"log/slog"
+	"time"
+)
+
+// minAgeInterval is the shortest interval at which page ages are checked.
+const minAgeInterval = time.Millisecond
+
+// WithMaxPageAge causes pages, including loaded standby pages, to be discarded
+// once they have been loaded for d, even if they have not been exhausted.  If
+// a standby page has expired all the pages are discarded, as by Reseed, and
+// the standby pages are loaded again as they are needed.  This bounds how long
+// any random byte sits in memory before it is used.  A goroutine checks the age
+// of the pages every quarter of d, so pages may be up to 1.25*d old when
+// discarded.  The goroutine is stopped by calling Close.  Values of d less than
+// or equal to 0 disable the limit.
+func WithMaxPageAge(d time.Duration) Option {
+	return func(r *CachedReader) {
+		r.maxAge = max(d, 0)
+	}
+}
+
+// watchAge discards pages that are older than r.maxAge until r is closed.
+func (r *CachedReader) watchAge() {
+	labelGoroutine("age")
+	t := time.NewTicker(max(r.maxAge/4, minAgeInterval))
+	defer t.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-t.C:
+		}
+		r.expire(time.Now().Add(-r.maxAge))
+	}
+}
+
+// expire discards the pages loaded before cutoff and advances to the next page.
+// If a standby page expired all the pages are discarded, rather than reloading
+// the standby page in place, as a concurrent swap may already have made it the
+// current page.  Errors from the source are left for the next Read to report.
+func (r *CachedReader) expire(cutoff time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	p := r.cur.Load()
+	for i, n := 1, r.standby(); i <= n; i++ {
+		gen := p.gen + uint64(i)
+		if r.bufs[gen%uint64(len(r.bufs))].loaded.Before(cutoff) {
+			r.log(slog.LevelDebug, "cachedrander: expired pages discarded", "gen", gen)
+			r.dropPages()
+			r.advance()
+			return
+		}
+	}
+	if p.retired.Load() || !p.buf.loaded.Before(cutoff) {
+		return
+	}
+	if !r.exhaust(p) {
+		// p was replaced while we were looking.
+		return
+	}
+	r.log(slog.LevelDebug, "cachedrander: expired page discarded", "gen", p.gen)
+	p.buf.invalidate()
+	clear(p.buf.data)
+	r.advance()
+}
@@ -0,0 +1,91 @@
+package cachedrander
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMaxPageAge(t *testing.T) {
+	g := &gen{size: 256}
+	r, err := New(g, 64, WithMaxPageAge(time.Hour), WithPageCount(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err := r.Warmup(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var buf [16]byte
+	r.Read(buf[:])
+
+	// Nothing has expired yet.
+	r.expire(time.Now().Add(-time.Hour))
+	if s := r.Stats(); s.Fills != 3 || s.WastedBytes != 0 {
+		t.Fatalf("got %d fills and %d bytes wasted, want 3 and 0", s.Fills, s.WastedBytes)
+	}
+
+	// Every page has expired.
+	old := r.cur.Load()
+	r.expire(time.Now().Add(time.Second))
+	s := r.Stats()
+	// All the pages are discarded and only the next page is loaded.
+	if s.Fills != 4 {
+		t.Errorf("got %d fills, want 4", s.Fills)
+	}
+	if n := r.standby(); n != 0 {
+		t.Errorf("got %d standby pages, want 0", n)
+	}
+	// 48 bytes of the current page and both standby pages.
+	if s.WastedBytes != 48+128 {
+		t.Errorf("got %d bytes wasted, want %d", s.WastedBytes, 48+128)
+	}
+	if p := r.cur.Load(); p.gen != old.gen+1 {
+		t.Errorf("got current page %d, want %d", p.gen, old.gen+1)
+	}
+	for _, b := range old.data {
+		if b != 0 {
+			t.Fatalf("expired page not cleared: %x", old.data)
+		}
+	}
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	if s := r.Stats(); s.BytesServed != 32 {
+		t.Errorf("got %d bytes served, want 32", s.BytesServed)
+	}
+
+	// An expired standby page is not reloaded in place: the pages are
+	// discarded and the generation following the current page is loaded.
+	if err := r.Warmup(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	cur := r.cur.Load()
+	r.bufs[(cur.gen+2)%3].loaded = time.Now().Add(-2 * time.Hour)
+	r.expire(time.Now().Add(-time.Hour))
+	if p := r.cur.Load(); p.gen != cur.gen+1 {
+		t.Errorf("got current page %d, want %d", p.gen, cur.gen+1)
+	}
+	if n := r.standby(); n != 0 {
+		t.Errorf("got %d standby pages, want 0", n)
+	}
+}
+
+func TestMaxPageAgeWatcher(t *testing.T) {
+	r, err := New(&gen{size: 256}, 64, WithMaxPageAge(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for r.Stats().Fills < 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("pages were not reloaded")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	r.Close()
+	var buf [16]byte
+	if _, err := r.Read(buf[:]); err != ErrClosed {
+		t.Errorf("got error %v, want %v", err, ErrClosed)
+	}
+}
@@ -0,0 +1,75 @@
+package cachedrander
+
+import (
+	"sync"
+	"time"
+)
+
+// WithParallelFill causes the background filler, Warmup, and New to load
+// several missing standby pages at once using up to workers concurrent reads
+// from the source, so recovering from a burst that consumed many pages is
+// limited by the source's bandwidth rather than its latency.  It is only useful
+// along with WithPageCount or WithWarmStandby.  The source must be safe for
+// concurrent use, as crypto/rand.Reader is.  Sources wrapped by options, such as
+// WithChaCha20, are read one page at a time.  Values of workers less than 2
+// disable parallel fills.
+func WithParallelFill(workers int) Option {
+	return func(r *CachedReader) {
+		r.workers = max(workers, 1)
+	}
+}
+
+// loadPages loads the count generations starting with first, reading up to
+// r.workers of them from the source at once.  The loads are completed in
+// order and loading stops at the first error, which is returned.  The pages
+// following a failed page are not stamped and must be loaded again.  r.mu must
+// be held.
+func (r *CachedReader) loadPages(first uint64, count int) error {
+	if r.workers < 2 || count < 2 {
+		for i := 0; i < count; i++ {
+			if err := r.load(first + uint64(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	type result struct {
+		b     *buffer
+		n     int
+		err   error
+		start time.Time
+	}
+	results := make([]result, count)
+	// Every page is prepared before any is read, since prepare may reseed
+	// the DRBGs wrapping the source while it is being read.
+	var prepErr error
+	for i := range results {
+		b, err := r.prepare(first + uint64(i))
+		if err != nil {
+			// Complete the loads already prepared.
+			prepErr, count = err, i
+			break
+		}
+		results[i].b = b
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, r.workers)
+	for i := range results[:count] {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(res *result) {
+			defer wg.Done()
+			labelGoroutine("load")
+			res.start = time.Now()
+			res.n, res.err = r.readPage(res.b.data)
+			<-sem
+		}(&results[i])
+	}
+	wg.Wait()
+	for i, res := range results[:count] {
+		if err := r.finish(first+uint64(i), res.b, res.n, res.err, res.start); err != nil {
+			return err
+		}
+	}
+	return prepErr
+}
@@ -0,0 +1,121 @@
+package cachedrander
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// concurrentReader is a slow source that is safe for concurrent use.  Each
+// Read fills buf with the number of the Read and records how many Reads were
+// in progress at once.
+type concurrentReader struct {
+	reads  atomic.Int64
+	active atomic.Int64
+	mu     sync.Mutex
+	most   int64
+}
+
+func (c *concurrentReader) Read(buf []byte) (int, error) {
+	n := c.active.Add(1)
+	c.mu.Lock()
+	c.most = max(c.most, n)
+	c.mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	id := byte(c.reads.Add(1))
+	for i := range buf {
+		buf[i] = id
+	}
+	c.active.Add(-1)
+	return len(buf), nil
+}
+
+// maxActive returns the most Reads that were in progress at once.
+func (c *concurrentReader) maxActive() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.most
+}
+
+func TestParallelFill(t *testing.T) {
+	c := &concurrentReader{}
+	r, err := New(c, 64, WithPageCount(6), WithParallelFill(3), WithLazyInit())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Warmup(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if c.reads.Load() != 6 {
+		t.Errorf("got %d reads, want 6", c.reads.Load())
+	}
+	if most := c.maxActive(); most != 3 {
+		t.Errorf("got %d concurrent reads, want 3", most)
+	}
+
+	// Every page holds the data of a single, distinct, read.
+	seen := map[byte]bool{}
+	var buf [16]byte
+	for i := 0; i < 6*4; i++ {
+		if _, err := r.Read(buf[:]); err != nil {
+			t.Fatal(err)
+		}
+		if i%4 == 0 {
+			if seen[buf[0]] {
+				t.Errorf("page %d repeats read %d", i/4, buf[0])
+			}
+			seen[buf[0]] = true
+		}
+		for _, b := range buf {
+			if b != buf[0] {
+				t.Fatalf("page %d mixes reads: %v", i/4, buf)
+			}
+		}
+	}
+}
+
+func TestParallelFillWrapped(t *testing.T) {
+	c := &concurrentReader{}
+	r, err := New(c, 64, WithWarmStandby(), WithPageCount(4), WithParallelFill(4), WithChaCha20(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var buf [16]byte
+	for i := 0; i < 32; i++ {
+		if _, err := r.Read(buf[:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if most := c.maxActive(); most != 1 {
+		t.Errorf("got %d concurrent reads of a wrapped source, want 1", most)
+	}
+}
+
+// TestParallelFillReseed runs under the race detector to verify that reseeding
+// a wrapped source does not race with the parallel loads reading it.
+func TestParallelFillReseed(t *testing.T) {
+	r, err := New(&concurrentReader{}, 64, WithPageCount(8), WithParallelFill(4),
+		WithChaCha20(0), WithReseedInterval(1), WithWarmStandby())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var buf [16]byte
+			for j := 0; j < 200; j++ {
+				if _, err := r.Read(buf[:]); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,101 @@
+package cachedrander
+
+import (
+	"errors"
+	"sync"
+)
+
+// defaultPKCS11Chunk is the default most data requested per C_GenerateRandom.
+const defaultPKCS11Chunk = 1024
+
+// errPKCS11Short is returned when C_GenerateRandom returns no data.
+var errPKCS11Short = errors.New("cachedrander: C_GenerateRandom returned no data")
+
+// A PKCS11Session is an open session with a PKCS#11 token.
+type PKCS11Session interface {
+	// GenerateRandom calls C_GenerateRandom for n bytes.
+	GenerateRandom(n int) ([]byte, error)
+
+	// Close closes the session.
+	Close() error
+}
+
+// A PKCS11Reader is a source that reads from an HSM using PKCS#11's
+// C_GenerateRandom.  It opens a session on first use and keeps it open.  If a
+// call fails the session is closed and the call retried on a new session, up
+// to Retries times.  Sessions are opened by Open, which typically calls
+// C_OpenSession and C_Login using a PKCS#11 library such as
+// github.com/miekg/pkcs11.
+//
+// A PKCS11Reader is safe for concurrent use.
+type PKCS11Reader struct {
+	// Open opens a new session.
+	Open func() (PKCS11Session, error)
+
+	// Retries is the number of times a failed call is retried.
+	Retries int
+
+	// ChunkSize is the most data requested per call.  It defaults to
+	// 1024 bytes.
+	ChunkSize int
+
+	mu      sync.Mutex
+	session PKCS11Session
+}
+
+// Read fills buf with data from the token.
+func (p *PKCS11Reader) Read(buf []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	chunk := p.ChunkSize
+	if chunk <= 0 {
+		chunk = defaultPKCS11Chunk
+	}
+	n := 0
+	for n < len(buf) {
+		b, err := p.generate(min(chunk, len(buf)-n))
+		if err != nil {
+			return n, err
+		}
+		n += copy(buf[n:], b)
+	}
+	return n, nil
+}
+
+// generate returns up to n bytes from the token, opening sessions and retrying
+// as needed.  p.mu must be held.
+func (p *PKCS11Reader) generate(n int) ([]byte, error) {
+	var err error
+	for try := 0; try <= p.Retries; try++ {
+		if p.session == nil {
+			if p.session, err = p.Open(); err != nil {
+				p.session = nil
+				continue
+			}
+		}
+		var b []byte
+		b, err = p.session.GenerateRandom(n)
+		if err == nil && len(b) == 0 {
+			err = errPKCS11Short
+		}
+		if err == nil {
+			return b, nil
+		}
+		// The session may no longer be usable.
+		p.session.Close()
+		p.session = nil
+	}
+	return nil, err
+}
+
+// Close closes the open session, if any.
+func (p *PKCS11Reader) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.session == nil {
+		return nil
+	}
+	err := p.session.Close()
+	p.session = nil
+	return err
+}
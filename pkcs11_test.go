@@ -0,0 +1,65 @@
+package cachedrander
+
+import (
+	"errors"
+	"testing"
+)
+
+// A testSession generates data from g and fails every fail'th call.
+type testSession struct {
+	g      *gen
+	fail   int
+	calls  *int
+	closed bool
+}
+
+func (s *testSession) GenerateRandom(n int) ([]byte, error) {
+	*s.calls++
+	if s.closed {
+		return nil, errors.New("session closed")
+	}
+	if s.fail > 0 && *s.calls%s.fail == 0 {
+		return nil, errors.New("device error")
+	}
+	b := make([]byte, n)
+	s.g.Read(b)
+	return b, nil
+}
+
+func (s *testSession) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestPKCS11Reader(t *testing.T) {
+	g := &gen{size: 1 << 20}
+	var calls, opens int
+	p := &PKCS11Reader{
+		Open: func() (PKCS11Session, error) {
+			opens++
+			return &testSession{g: g, fail: 5, calls: &calls}, nil
+		},
+		Retries:   1,
+		ChunkSize: 16,
+	}
+	r, err := New(p, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	r.Max = 8
+	checkSequential(t, r)
+	if opens < 2 {
+		t.Errorf("got %d sessions opened, want at least 2", opens)
+	}
+
+	p = &PKCS11Reader{
+		Open: func() (PKCS11Session, error) {
+			return &testSession{g: g, fail: 1, calls: &calls}, nil
+		},
+		Retries: 3,
+	}
+	if _, err := New(p, 256); err == nil {
+		t.Error("failing token did not return an error")
+	}
+}
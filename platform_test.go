@@ -0,0 +1,31 @@
+package cachedrander
+
+import (
+	"testing"
+	"unsafe"
+)
+
+// TestAlignment verifies that the 64 bit values accessed atomically are 64 bit
+// aligned, which is required on 32 bit platforms.
+func TestAlignment(t *testing.T) {
+	r, err := New(&gen{size: 256}, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := r.cur.Load()
+	for _, v := range []struct {
+		name string
+		addr unsafe.Pointer
+	}{
+		{"maxRead", unsafe.Pointer(&r.maxRead)},
+		{"size", unsafe.Pointer(&r.size)},
+		{"served", unsafe.Pointer(&r.served)},
+		{"forkGen", unsafe.Pointer(&r.forkGen)},
+		{"offset", unsafe.Pointer(&p.offset)},
+		{"stamp", unsafe.Pointer(&p.buf.stamp)},
+	} {
+		if addr := uintptr(v.addr); addr%8 != 0 {
+			t.Errorf("%s is at %#x, which is not 64 bit aligned", v.name, addr)
+		}
+	}
+}
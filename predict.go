@@ -0,0 +1,93 @@
+package cachedrander
+
+import "time"
+
+// fillMargin is how many times longer than the average page load a predictive
+// fill is started before the current page is expected to be exhausted.
+const fillMargin = 2
+
+// A predictor estimates when the standby page must be loaded.  It is only used
+// by the background filler goroutine.
+type predictor struct {
+	last   time.Time     // time of the last sample
+	served uint64        // bytes served as of last
+	rate   float64       // average bytes served per second
+	fill   time.Duration // average time to load a page
+}
+
+// WithPredictiveFill starts a background filler, like WithBackgroundFill, that
+// tracks the rate at which data is served and how long the source takes to load
+// a page.  Rather than loading the standby page as soon as possible, or at a
+// fixed watermark, it loads the standby page so it is ready shortly before the
+// current page is expected to be exhausted.  This spreads loads of the source
+// evenly under steadily increasing load and keeps retired pages intact longer.
+// If the current page is exhausted sooner than predicted the standby page is
+// loaded immediately.  WithPredictiveFill has no effect along with
+// WithWarmStandby.
+func WithPredictiveFill() Option {
+	return func(r *CachedReader) {
+		r.predict = &predictor{}
+		r.fillAt = 1
+		r.refill = make(chan struct{}, 1)
+	}
+}
+
+// sample updates the average rate at which r serves data.
+func (pr *predictor) sample(r *CachedReader) {
+	now := time.Now()
+	p := r.cur.Load()
+	served := r.served.Load()
+	if !p.retired.Load() {
+		served += r.used(p)
+	}
+	if !pr.last.IsZero() && now.After(pr.last) && served >= pr.served {
+		rate := float64(served-pr.served) / now.Sub(pr.last).Seconds()
+		if pr.rate == 0 {
+			pr.rate = rate
+		} else {
+			pr.rate = (pr.rate + rate) / 2
+		}
+	}
+	pr.last, pr.served = now, served
+}
+
+// loaded records that loading a page took d.
+func (pr *predictor) loaded(d time.Duration) {
+	if pr.fill == 0 {
+		pr.fill = d
+	} else {
+		pr.fill = (pr.fill + d) / 2
+	}
+}
+
+// delay returns how long to wait before loading the standby page so it is
+// loaded just before the current page is exhausted.
+func (pr *predictor) delay(r *CachedReader) time.Duration {
+	if pr.rate <= 0 {
+		return 0
+	}
+	p := r.cur.Load()
+	remaining := float64(p.size - r.used(p))
+	d := time.Duration(remaining/pr.rate*float64(time.Second)) - fillMargin*pr.fill
+	return max(d, 0)
+}
+
+// predictiveWait waits until the standby page should be loaded.  It returns
+// early if r.refill is signaled, which happens when a Read exhausts the
+// current page, and reports false if r was closed.
+func (r *CachedReader) predictiveWait() bool {
+	r.predict.sample(r)
+	d := r.predict.delay(r)
+	if d == 0 {
+		return true
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-r.done:
+		return false
+	case <-r.refill:
+	case <-t.C:
+	}
+	return true
+}
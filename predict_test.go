@@ -0,0 +1,67 @@
+package cachedrander
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPredictiveFill(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 160, WithPredictiveFill(), WithMax(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	checkSequential(t, r)
+
+	// Read at a steady pace so the filler has a rate to predict from.
+	var buf [16]byte
+	for i := 0; i < 100; i++ {
+		if _, err := r.Read(buf[:]); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := r.Stats().BytesServed; got != 2016+1600 {
+		t.Errorf("got %d bytes served, want %d", got, 2016+1600)
+	}
+}
+
+func TestPredictorDelay(t *testing.T) {
+	r, err := New(&gen{size: 256}, 1600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pr predictor
+	if d := pr.delay(r); d != 0 {
+		t.Errorf("without a rate got delay %v, want 0", d)
+	}
+
+	// Serving 1600 bytes a second, with 400 bytes of the page used, the page
+	// lasts another 750ms.
+	pr.rate = 1600
+	var buf [16]byte
+	for i := 0; i < 25; i++ {
+		r.Read(buf[:])
+	}
+	if d := pr.delay(r); d != 750*time.Millisecond {
+		t.Errorf("got delay %v, want 750ms", d)
+	}
+	pr.loaded(100 * time.Millisecond)
+	if d := pr.delay(r); d != 550*time.Millisecond {
+		t.Errorf("got delay %v, want 550ms", d)
+	}
+	pr.loaded(time.Second)
+	if d := pr.delay(r); d != 0 {
+		t.Errorf("with a slow source got delay %v, want 0", d)
+	}
+
+	pr = predictor{}
+	pr.sample(r)
+	time.Sleep(10 * time.Millisecond)
+	r.Read(buf[:])
+	pr.sample(r)
+	if pr.rate <= 0 || pr.rate > 1600 {
+		t.Errorf("got rate %v, want between 0 and 1600", pr.rate)
+	}
+}
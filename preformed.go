@@ -0,0 +1,29 @@
+package cachedrander
+
+// WithPreformedUUIDs causes each page to be formatted as a sequence of random
+// (version 4) UUIDs when it is loaded: the version and variant bits of every
+// 16 byte block are set before any of the page is served.  Read16 then returns
+// finished UUIDs that need no further processing.  The size of the cache is
+// rounded up to a multiple of 16.
+//
+// Six bits of every 16 bytes are no longer random, so a CachedReader using
+// WithPreformedUUIDs should only be used to generate version 4 UUIDs, and only
+// by reads that are multiples of 16 bytes.
+func WithPreformedUUIDs() Option {
+	return func(r *CachedReader) {
+		r.preformed = true
+	}
+}
+
+// PreformedUUIDs reports whether r was created with WithPreformedUUIDs.
+func (r *CachedReader) PreformedUUIDs() bool {
+	return r.preformed
+}
+
+// preform sets the version 4 and variant bits of each 16 byte block of data.
+func preform(data []byte) {
+	for i := 0; i+16 <= len(data); i += 16 {
+		data[i+6] = (data[i+6] & 0x0f) | 0x40 // Version 4
+		data[i+8] = (data[i+8] & 0x3f) | 0x80 // Variant is 10
+	}
+}
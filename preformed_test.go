@@ -0,0 +1,33 @@
+package cachedrander
+
+import "testing"
+
+func TestPreformedUUIDs(t *testing.T) {
+	g := &gen{size: 17}
+	// The size is rounded up to 48 so no UUID straddles a page.
+	r, err := New(g, 40, WithMax(8), WithPreformedUUIDs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.size.Load() != 48 {
+		t.Errorf("got size %d, want 48", r.size.Load())
+	}
+	if !r.PreformedUUIDs() {
+		t.Error("PreformedUUIDs returned false")
+	}
+	for i := 0; i < 10; i++ {
+		b, err := r.Read16()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b[6]>>4 != 4 {
+			t.Errorf("UUID %d: got version %d, want 4", i, b[6]>>4)
+		}
+		if b[8]>>6 != 2 {
+			t.Errorf("UUID %d: got variant bits %b, want 10", i, b[8]>>6)
+		}
+		if want := byte(i * 16); b[0] != want {
+			t.Errorf("UUID %d: got data starting at %d, want %d", i, b[0], want)
+		}
+	}
+}
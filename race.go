@@ -0,0 +1,69 @@
+package cachedrander
+
+import (
+	"math"
+	"time"
+)
+
+// preemptWindow is how long a preempted Read is assumed to be descheduled: the
+// Go scheduler's time slice.
+const preemptWindow = 10 * time.Millisecond
+
+// EstimateRaceProbability estimates the probability that a single Read races
+// with the reloading of its page, as described in the package documentation,
+// causing its data to be discarded and the Read retried.  It can be used to
+// choose the size passed to New, or n passed to NewUUIDReader, for a target
+// probability rather than guessing.
+//
+// pageSize is the number of bytes that must be served before a page is reused:
+// the page size for the default two pages, or the page size times one less than
+// the count given to WithPageCount.  maxRead is the size of each Read (16 for
+// UUIDs), goroutines is the number of goroutines calling Read, and qps is the
+// total number of Reads per second.
+//
+// The estimate is pessimistic: it assumes every Read is preempted for a full
+// scheduler time slice between reserving and copying its data, and that the
+// other Reads arrive at random (a Poisson process).  The race occurs if enough
+// Reads arrive during that time to exhaust pageSize bytes.
+func EstimateRaceProbability(pageSize, maxRead, goroutines int, qps float64) float64 {
+	if goroutines < 2 || qps <= 0 {
+		return 0
+	}
+	if maxRead < 1 {
+		maxRead = 1
+	}
+	// k is the number of Reads by other goroutines needed to exhaust the
+	// pages, lambda the expected number of them during the window.
+	k := (pageSize + maxRead - 1) / maxRead
+	if k < 1 {
+		return 1
+	}
+	lambda := qps * float64(goroutines-1) / float64(goroutines) * preemptWindow.Seconds()
+	return poissonTail(lambda, k)
+}
+
+// poissonTail returns the probability that a Poisson distributed value with
+// mean lambda is at least k.
+func poissonTail(lambda float64, k int) float64 {
+	// logTerm returns the log of the probability of exactly i.
+	logTerm := func(i int) float64 {
+		lg, _ := math.Lgamma(float64(i) + 1)
+		return -lambda + float64(i)*math.Log(lambda) - lg
+	}
+	if lambda < float64(k) {
+		// The terms from k on decrease, sum them until they vanish.
+		var sum float64
+		term := math.Exp(logTerm(k))
+		for i := k; term > sum*1e-17 && term > 0; i++ {
+			sum += term
+			term *= lambda / float64(i+1)
+		}
+		return math.Min(sum, 1)
+	}
+	// The terms below k increase, sum them and take the complement.
+	var sum float64
+	for i := 0; i < k; i++ {
+		sum += math.Exp(logTerm(i))
+	}
+	return math.Max(1-sum, 0)
+}
@@ -0,0 +1,52 @@
+package cachedrander
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPoissonTail(t *testing.T) {
+	for _, tt := range []struct {
+		lambda float64
+		k      int
+		want   float64
+	}{
+		{1, 0, 1},
+		{1, 1, 1 - math.Exp(-1)},
+		{1, 2, 1 - 2*math.Exp(-1)},
+		{2, 1, 1 - math.Exp(-2)},
+		{2, 3, 1 - 5*math.Exp(-2)},
+		{0.5, 3, 1 - 1.625*math.Exp(-0.5)},
+	} {
+		if got := poissonTail(tt.lambda, tt.k); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("poissonTail(%v, %d) got %v, want %v", tt.lambda, tt.k, got, tt.want)
+		}
+	}
+}
+
+func TestEstimateRaceProbability(t *testing.T) {
+	if p := EstimateRaceProbability(1600, 16, 1, 1e6); p != 0 {
+		t.Errorf("single goroutine got %v, want 0", p)
+	}
+	if p := EstimateRaceProbability(1600, 16, 8, 0); p != 0 {
+		t.Errorf("no reads got %v, want 0", p)
+	}
+	if p := EstimateRaceProbability(0, 16, 8, 1e6); p != 1 {
+		t.Errorf("empty page got %v, want 1", p)
+	}
+	// Larger pages must be less likely to race and more traffic more likely.
+	last := 1.0
+	for _, n := range []int{10, 100, 1000, 10000} {
+		p := EstimateRaceProbability(n*16, 16, 8, 1e5)
+		if p < 0 || p > last {
+			t.Errorf("%d UUIDs got %v, previous %v", n, p, last)
+		}
+		last = p
+	}
+	if last > 1e-100 {
+		t.Errorf("10000 UUIDs got %v, want about 0", last)
+	}
+	if lo, hi := EstimateRaceProbability(1600, 16, 8, 1e4), EstimateRaceProbability(1600, 16, 8, 1e5); lo >= hi {
+		t.Errorf("more traffic got %v, less %v", hi, lo)
+	}
+}
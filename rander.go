@@ -0,0 +1,86 @@
+package cachedrander
+
+import (
+	"crypto/rand"
+	"io"
+)
+
+// A Rander is the interface of the methods most code uses to read random data
+// from a CachedReader.  Code that depends on a Rander, rather than on a
+// CachedReader, can be given a Passthrough or a FailingRander in tests.
+type Rander interface {
+	io.Reader
+	Read16() ([16]byte, error)
+	ReadN(dst []byte, n int) error
+}
+
+var (
+	_ Rander = (*CachedReader)(nil)
+	_ Rander = Passthrough{}
+	_ Rander = FailingRander{}
+)
+
+// A Passthrough is a Rander that reads directly from R, without caching.  If R
+// is nil crypto/rand.Reader is used.
+type Passthrough struct {
+	R io.Reader
+}
+
+// reader returns the reader to read from.
+func (p Passthrough) reader() io.Reader {
+	if p.R == nil {
+		return rand.Reader
+	}
+	return p.R
+}
+
+// Read reads from R.
+func (p Passthrough) Read(buf []byte) (int, error) {
+	return p.reader().Read(buf)
+}
+
+// Read16 returns 16 bytes read from R.
+func (p Passthrough) Read16() ([16]byte, error) {
+	var b [16]byte
+	if _, err := io.ReadFull(p.reader(), b[:]); err != nil {
+		return [16]byte{}, err
+	}
+	return b, nil
+}
+
+// ReadN fills dst[:n*16] with data read from R.  ReadN panics if dst is shorter
+// than n*16 bytes.
+func (p Passthrough) ReadN(dst []byte, n int) error {
+	_, err := io.ReadFull(p.reader(), dst[:n*16])
+	return err
+}
+
+// A FailingRander is a Rander whose methods always fail with Err, or with
+// ErrFillFailed if Err is nil.  It lets tests exercise the handling of a
+// CachedReader whose source has failed.
+type FailingRander struct {
+	Err error
+}
+
+// err returns the error to fail with.
+func (f FailingRander) err() error {
+	if f.Err == nil {
+		return ErrFillFailed
+	}
+	return f.Err
+}
+
+// Read returns 0 and the error.
+func (f FailingRander) Read(buf []byte) (int, error) {
+	return 0, f.err()
+}
+
+// Read16 returns the error.
+func (f FailingRander) Read16() ([16]byte, error) {
+	return [16]byte{}, f.err()
+}
+
+// ReadN returns the error.
+func (f FailingRander) ReadN(dst []byte, n int) error {
+	return f.err()
+}
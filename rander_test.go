@@ -0,0 +1,52 @@
+package cachedrander
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPassthrough(t *testing.T) {
+	g := &gen{size: 64}
+	var r Rander = Passthrough{R: g}
+	b, err := r.Read16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := make([]byte, 32)
+	if err := r.ReadN(dst, 2); err != nil {
+		t.Fatal(err)
+	}
+	var want [48]byte
+	for i := range want {
+		want[i] = byte(i)
+	}
+	if got := append(b[:], dst...); !bytes.Equal(got, want[:]) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+	if _, err := (Passthrough{}).Read16(); err != nil {
+		t.Errorf("crypto/rand: %v", err)
+	}
+}
+
+func TestFailingRander(t *testing.T) {
+	errTest := errors.New("test")
+	for _, tt := range []struct {
+		r    Rander
+		want error
+	}{
+		{FailingRander{}, ErrFillFailed},
+		{FailingRander{Err: errTest}, errTest},
+	} {
+		var buf [16]byte
+		if _, err := tt.r.Read(buf[:]); err != tt.want {
+			t.Errorf("Read got error %v, want %v", err, tt.want)
+		}
+		if _, err := tt.r.Read16(); err != tt.want {
+			t.Errorf("Read16 got error %v, want %v", err, tt.want)
+		}
+		if err := tt.r.ReadN(buf[:], 1); err != tt.want {
+			t.Errorf("ReadN got error %v, want %v", err, tt.want)
+		}
+	}
+}
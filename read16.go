@@ -0,0 +1,57 @@
+package cachedrander
+
+import "context"
+
+// Read16 returns 16 bytes of cached data, the size of a UUID.  Unlike Read,
+// Read16 never returns a short read: if the 16 bytes would straddle the end of
+// the current page the rest are taken from the next page.
+func (r *CachedReader) Read16() ([16]byte, error) {
+	if err := r.checkFork(); err != nil {
+		return [16]byte{}, err
+	}
+	for {
+		p := r.cur.Load()
+		end := p.offset.Add(16)
+		if end <= p.size && r.plainCopy() {
+			// The buffer is pinned, as by copyAt, while the UUID is
+			// copied.
+			p.buf.pins.Add(1)
+			if p.buf.stamp.Load() != p.gen {
+				p.buf.pins.Add(-1)
+				// The page was reloaded out from under us.
+				r.skipped.Add(16)
+				continue
+			}
+			b := [16]byte(p.data[end-16 : end])
+			p.buf.pins.Add(-1)
+			r.dups.check(b[:])
+			r.checkWatermark(p, end-16, end)
+			return b, nil
+		}
+		if end <= p.size {
+			var b [16]byte
+			if _, ok := r.copyAt(b[:], p, end-16); !ok {
+				// The page was reloaded out from under us.
+				r.skipped.Add(16)
+				continue
+			}
+			r.checkWatermark(p, end-16, end)
+			return b, nil
+		}
+		if end-16 < p.size {
+			var b [16]byte
+			ok, err := r.straddle(context.Background(), b[:], p, end-16)
+			if !ok {
+				continue
+			}
+			return b, err
+		}
+		if err := r.waitContext(context.Background(), p); err != nil {
+			var b [16]byte
+			if r.fallBack(err, b[:]) {
+				return b, nil
+			}
+			return [16]byte{}, err
+		}
+	}
+}
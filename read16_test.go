@@ -0,0 +1,62 @@
+package cachedrander
+
+import "testing"
+
+func TestRead16(t *testing.T) {
+	g := &gen{size: 17}
+	// The page size is not a multiple of 16 so some reads straddle the end
+	// of a page.  No data is skipped.
+	r, err := New(g, 40, WithMax(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		b, err := r.Read16()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := byte(i * 16)
+		for j, c := range b {
+			if c != want+byte(j) {
+				t.Fatalf("block %d: got %v, want bytes starting at %d", i, b, want)
+			}
+		}
+	}
+	s := r.Stats()
+	if s.BytesServed != 10*16 {
+		t.Errorf("BytesServed got %d, want %d", s.BytesServed, 10*16)
+	}
+	if s.WastedBytes != 0 {
+		t.Errorf("WastedBytes got %d, want 0", s.WastedBytes)
+	}
+}
+
+func BenchmarkRead16(b *testing.B) {
+	r, err := NewUUIDReader(1000)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		if _, err := r.Read16(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkRead16Parallel measures contention between CPUs calling Read16.  Run
+// it with -cpu to see how well Read16 scales on many-core machines.
+func BenchmarkRead16Parallel(b *testing.B) {
+	r, err := NewUUIDReader(1000, WithWarmStandby())
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer r.Close()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := r.Read16(); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
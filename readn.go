@@ -0,0 +1,97 @@
+package cachedrander
+
+import (
+	"context"
+	"slices"
+)
+
+// ReadN fills dst[:n*16] with n UUIDs' worth of cached data.  As long as n*16
+// is no larger than what remains of the current page the data is reserved with
+// a single atomic operation, amortizing its cost over all n UUIDs.  Larger
+// requests are served one page's worth at a time.  ReadN panics if dst is
+// shorter than n*16 bytes.
+func (r *CachedReader) ReadN(dst []byte, n int) error {
+	return r.readChunks(context.Background(), dst[:n*16])
+}
+
+// Fill fills all of buf with cached data, taking data from as many pages as
+// needed, or returns an error.  It is equivalent to calling io.ReadFull on r
+// without the Read calls being limited to Max bytes.
+func (r *CachedReader) Fill(buf []byte) error {
+	return r.readChunks(context.Background(), buf)
+}
+
+// AppendRandom appends n bytes of cached data to dst and returns the extended
+// slice.  The data is copied directly into dst, which is grown if needed.  On
+// error dst is returned unchanged.
+func (r *CachedReader) AppendRandom(dst []byte, n int) ([]byte, error) {
+	out := slices.Grow(dst, n)[:len(dst)+n]
+	if err := r.readChunks(context.Background(), out[len(dst):]); err != nil {
+		return dst, err
+	}
+	return out, nil
+}
+
+// readChunks fills dst one page's worth at a time.
+func (r *CachedReader) readChunks(ctx context.Context, dst []byte) error {
+	for len(dst) > 0 {
+		chunk := min(uint64(len(dst)), r.size.Load())
+		if err := r.readFull(ctx, dst[:chunk]); err != nil {
+			return err
+		}
+		dst = dst[chunk:]
+	}
+	return nil
+}
+
+// readFull fills buf, which must be no larger than a page.  If buf would
+// straddle the end of the current page it is filled with the rest of the page
+// followed by data from the next page.
+func (r *CachedReader) readFull(ctx context.Context, buf []byte) error {
+	if err := r.checkFork(); err != nil {
+		return err
+	}
+	blen := uint64(len(buf))
+	for {
+		p := r.cur.Load()
+		end := p.offset.Add(blen)
+		start := end - blen
+		if end <= p.size {
+			if _, ok := r.copyAt(buf, p, start); !ok {
+				// The page was reloaded out from under us.
+				r.skipped.Add(blen)
+				continue
+			}
+			r.checkWatermark(p, start, end)
+			return nil
+		}
+		if start < p.size {
+			ok, err := r.straddle(ctx, buf, p, start)
+			if !ok {
+				continue
+			}
+			return err
+		}
+		if err := r.waitContext(ctx, p); err != nil {
+			if r.fallBack(err, buf) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// straddle fills buf, which was reserved at offset start of p but extends past
+// its end, with the rest of p followed by data from the next page.  This
+// prevents the tail of the page from being wasted.  straddle reports false if
+// the page was reloaded before the tail was copied, in which case the caller
+// must start over.
+func (r *CachedReader) straddle(ctx context.Context, buf []byte, p *page, start uint64) (bool, error) {
+	n := p.size - start
+	if _, ok := r.copyAt(buf[:n], p, start); !ok {
+		r.skipped.Add(n)
+		return false, nil
+	}
+	r.checkWatermark(p, start, p.size)
+	return true, r.readFull(ctx, buf[n:])
+}
@@ -0,0 +1,79 @@
+package cachedrander
+
+import "testing"
+
+func TestReadN(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		n     int
+		start byte // first byte expected
+	}{
+		{n: 2, start: 0},
+		{n: 3, start: 32},  // straddles pages 0 and 1
+		{n: 1, start: 80},  // from page 1
+		{n: 6, start: 96},  // spans pages 1, 2, and 3
+		{n: 1, start: 192}, // from page 3
+	} {
+		buf := make([]byte, tt.n*16)
+		if err := r.ReadN(buf, tt.n); err != nil {
+			t.Fatal(err)
+		}
+		for j, c := range buf {
+			if c != tt.start+byte(j) {
+				t.Fatalf("ReadN(%d): got %v, want bytes starting at %d", tt.n, buf, tt.start)
+			}
+		}
+	}
+}
+
+func TestAppendRandom(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := []byte("hdr:")
+	// Appends may be larger than a page.
+	dst, err = r.AppendRandom(dst, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 104 || string(dst[:4]) != "hdr:" {
+		t.Fatalf("got %q", dst)
+	}
+	for i, c := range dst[4:] {
+		if c != byte(i) {
+			t.Fatalf("byte %d: got %d, want %d", i, c, i)
+		}
+	}
+	r.Close()
+	if out, err := r.AppendRandom(dst, 16); err == nil || len(out) != len(dst) {
+		t.Errorf("closed reader: got %d bytes and error %v", len(out), err)
+	}
+}
+
+func TestFill(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The buffer spans three pages and is not a multiple of Max.
+	buf := make([]byte, 150)
+	if err := r.Fill(buf); err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range buf {
+		if c != byte(i) {
+			t.Fatalf("byte %d: got %d, want %d", i, c, i)
+		}
+	}
+	r.Close()
+	if err := r.Fill(buf); err != ErrClosed {
+		t.Errorf("got error %v, want %v", err, ErrClosed)
+	}
+}
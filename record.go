@@ -0,0 +1,128 @@
+package cachedrander
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// ErrBadRecording is wrapped by the error returned by a ReplaySource whose
+// recording is truncated or out of sequence.
+var ErrBadRecording = errors.New("cachedrander: invalid recording")
+
+// recordHeader is the size of the header of each record: an 8 byte sequence
+// number followed by a 4 byte length, both big-endian.
+const recordHeader = 12
+
+// WithRecorder causes every block of data served by the CachedReader, by Read,
+// Read16, Next, or any other method, to be written to w as a record holding
+// the block's sequence number, starting at 0, its length, and the data.  The
+// recording can be served again by a ReplaySource to reproduce a bug that
+// depends on the IDs that were generated.  Records are written in the order of
+// their sequence numbers, while holding a mutex, so w need not be safe for
+// concurrent use but should be buffered (e.g., a bufio.Writer that the
+// application flushes after Close).  Recording stops, and a warning is logged
+// (see WithLogger), if w returns an error.  Every CachedReader the Option is
+// applied to, such as each shard created by NewSharded, shares the one
+// recording.
+//
+// The recording contains every random byte served and must be protected
+// accordingly.
+func WithRecorder(w io.Writer) Option {
+	rec := &recorder{w: w}
+	return func(r *CachedReader) {
+		r.recorder = rec
+	}
+}
+
+// A recorder writes the records for WithRecorder.
+type recorder struct {
+	mu  sync.Mutex
+	w   io.Writer
+	seq uint64
+	err error
+	buf []byte // the record being written
+}
+
+// record writes buf, which is being served, to the recording, if any.
+func (r *CachedReader) record(buf []byte) {
+	rec := r.recorder
+	if rec == nil || len(buf) == 0 {
+		return
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.err != nil {
+		return
+	}
+	// Copying buf into the record keeps it from escaping, which would add
+	// an allocation to every read.
+	rec.buf = binary.BigEndian.AppendUint64(rec.buf[:0], rec.seq)
+	rec.buf = binary.BigEndian.AppendUint32(rec.buf, uint32(len(buf)))
+	rec.buf = append(rec.buf, buf...)
+	if _, rec.err = rec.w.Write(rec.buf); rec.err != nil {
+		r.log(slog.LevelWarn, "cachedrander: recording stopped", "seq", rec.seq, "error", rec.err)
+		return
+	}
+	rec.seq++
+}
+
+// A ReplaySource is a source that serves the data recorded by WithRecorder.
+// It serves the blocks in sequence, as a single stream, and returns io.EOF at
+// the end of the recording.  Reading it directly, or through a CachedReader
+// that is read the same way as the recorded one, reproduces the recorded IDs.
+// A ReplaySource is not safe for concurrent use.
+type ReplaySource struct {
+	r    io.Reader
+	seq  uint64 // sequence number of the next record
+	left uint32 // bytes left in the current record
+	hdr  [recordHeader]byte
+}
+
+// NewReplaySource returns a ReplaySource that serves the recording read from
+// r.
+func NewReplaySource(r io.Reader) *ReplaySource {
+	return &ReplaySource{r: r}
+}
+
+// Read fills buf with the next len(buf) bytes of recorded data.  It returns an
+// error wrapping ErrBadRecording if the recording is truncated or a record is
+// out of sequence.
+func (s *ReplaySource) Read(buf []byte) (int, error) {
+	n := 0
+	for n < len(buf) {
+		if s.left == 0 {
+			_, err := io.ReadFull(s.r, s.hdr[:])
+			switch {
+			case err == io.EOF && n > 0:
+				return n, nil
+			case err == io.EOF:
+				return 0, io.EOF
+			case err == io.ErrUnexpectedEOF:
+				return n, fmt.Errorf("%w: truncated header of record %d", ErrBadRecording, s.seq)
+			case err != nil:
+				return n, err
+			}
+			if seq := binary.BigEndian.Uint64(s.hdr[:8]); seq != s.seq {
+				return n, fmt.Errorf("%w: got record %d, want %d", ErrBadRecording, seq, s.seq)
+			}
+			s.left = binary.BigEndian.Uint32(s.hdr[8:])
+			s.seq++
+			continue
+		}
+		m := min(len(buf)-n, int(s.left))
+		c, err := io.ReadFull(s.r, buf[n:n+m])
+		n += c
+		s.left -= uint32(c)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return n, fmt.Errorf("%w: truncated record %d", ErrBadRecording, s.seq-1)
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
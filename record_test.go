@@ -0,0 +1,142 @@
+package cachedrander
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	var rec bytes.Buffer
+	r, err := New(&gen{size: 64}, 64, WithRecorder(&rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var served []byte
+	for i := 0; i < 4; i++ {
+		b, err := r.Read16()
+		if err != nil {
+			t.Fatal(err)
+		}
+		served = append(served, b[:]...)
+	}
+	var buf [16]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	served = append(served, buf[:]...)
+	b, release, err := r.Next(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	served = append(served, b...)
+	release()
+	r.Close()
+
+	if n, want := rec.Len(), len(served)+6*recordHeader; n != want {
+		t.Errorf("recorded %d bytes, want %d", n, want)
+	}
+	recording := bytes.Clone(rec.Bytes())
+	got, err := io.ReadAll(NewReplaySource(&rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, served) {
+		t.Errorf("replayed\n%x\nwant\n%x", got, served)
+	}
+
+	// A CachedReader read the same way reproduces the recorded pages.
+	r, err = New(NewReplaySource(bytes.NewReader(recording)), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		b, err := r.Read16()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b[:], served[16*i:16*(i+1)]) {
+			t.Errorf("block %d: got %x, want %x", i, b, served[16*i:16*(i+1)])
+		}
+	}
+}
+
+func TestReplaySourceErrors(t *testing.T) {
+	record := func(seq uint64, data []byte) []byte {
+		hdr := make([]byte, recordHeader)
+		binary.BigEndian.PutUint64(hdr, seq)
+		binary.BigEndian.PutUint32(hdr[8:], uint32(len(data)))
+		return append(hdr, data...)
+	}
+	good := append(record(0, []byte("abc")), record(1, []byte("de"))...)
+	for _, tt := range []struct {
+		name string
+		in   []byte
+		want error
+	}{
+		{"complete", good, nil},
+		{"truncated header", good[:len(good)-4], ErrBadRecording},
+		{"truncated data", good[:len(good)-1], ErrBadRecording},
+		{"out of sequence", append(record(0, []byte("abc")), record(2, []byte("de"))...), ErrBadRecording},
+	} {
+		got, err := io.ReadAll(NewReplaySource(bytes.NewReader(tt.in)))
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.want)
+		}
+		if tt.want == nil && string(got) != "abcde" {
+			t.Errorf("%s: got %q, want %q", tt.name, got, "abcde")
+		}
+	}
+}
+
+func TestRecorderDirect(t *testing.T) {
+	var rec bytes.Buffer
+	r, err := New(&gen{size: 64}, 64, WithRecorder(&rec), WithFullReads(), WithDirectKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 128)
+	if _, err := r.Read(buf); err != nil {
+		t.Fatal(err)
+	}
+	key, err := r.GenerateKey(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	got, err := io.ReadAll(NewReplaySource(&rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := append(buf, key...); !bytes.Equal(got, want) {
+		t.Errorf("replayed\n%x\nwant\n%x", got, want)
+	}
+}
+
+func TestRecorderSharded(t *testing.T) {
+	var rec bytes.Buffer
+	s, err := NewSharded(&gen{size: 64}, 64, 4, WithRecorder(&rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var served int
+	for i := 0; i < 64; i++ {
+		var buf [16]byte
+		n, err := s.Read(buf[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		served += n
+	}
+	s.Close()
+	// The shards write a single recording, in sequence.
+	got, err := io.ReadAll(NewReplaySource(&rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != served {
+		t.Errorf("replayed %d bytes, want %d", len(got), served)
+	}
+}
@@ -0,0 +1,50 @@
+package cachedrander
+
+import "log/slog"
+
+// A drbg is a source, such as the one used by WithChaCha20, that expands a seed
+// read from its own source.
+type drbg interface {
+	// requestReseed causes a new seed to be read before the next output
+	// is generated.
+	requestReseed()
+}
+
+// WithReseedInterval causes the DRBG used by WithChaCha20 or WithCTRDRBG to be
+// reseeded from its source once n bytes have been served since it was last
+// reseeded by WithReseedInterval, regardless of how much output the DRBG has
+// generated.  The quota is checked each time a page is loaded, so pages that
+// were already loaded are still served and up to the size of the cache may be
+// served beyond n before data from the new seed is served.  WithReseedInterval
+// has no effect without a DRBG or if n is 0.
+func WithReseedInterval(n uint64) Option {
+	return func(r *CachedReader) {
+		r.reseedEvery = n
+	}
+}
+
+// checkReseed requests that the DRBGs reseed if at least r.reseedEvery bytes
+// have been served since they last did.  r.mu must be held.
+func (r *CachedReader) checkReseed() {
+	if r.reseedEvery == 0 || len(r.drbgs) == 0 {
+		return
+	}
+	served := r.served.Load()
+	if p := r.cur.Load(); p != nil && !p.retired.Load() {
+		served += r.used(p)
+	}
+	if served-r.seedServed < r.reseedEvery {
+		return
+	}
+	r.reseedDRBGs()
+	r.seedServed = served
+	r.log(slog.LevelDebug, "cachedrander: reseed interval reached", "served", served)
+}
+
+// reseedDRBGs requests that the DRBGs read a new seed before generating any
+// more output.  r.mu must be held.
+func (r *CachedReader) reseedDRBGs() {
+	for _, d := range r.drbgs {
+		d.requestReseed()
+	}
+}
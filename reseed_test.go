@@ -0,0 +1,88 @@
+package cachedrander
+
+import "testing"
+
+func TestReseedInterval(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opt  Option
+	}{
+		{"ChaCha20", WithChaCha20(0)},
+		{"CTR_DRBG", WithCTRDRBG(nil, 0)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &failingReader{r: &gen{size: 256}}
+			r, err := New(f, 64, tt.opt, WithReseedInterval(128))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(r.drbgs) != 1 {
+				t.Fatalf("got %d DRBGs, want 1", len(r.drbgs))
+			}
+			// Each page holds 4 reads.  The 9th read loads the
+			// third page after 128 bytes were served and the 17th
+			// read loads the fifth page after 256.
+			var buf [16]byte
+			for i, want := range []int{1, 1, 1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 2, 2, 2, 2, 3} {
+				if _, err := r.Read(buf[:]); err != nil {
+					t.Fatal(err)
+				}
+				if f.reads != want {
+					t.Errorf("read %d: got %d seeds, want %d", i+1, f.reads, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReseedIntervalNoDRBG(t *testing.T) {
+	f := &failingReader{r: &gen{size: 256}}
+	r, err := New(f, 64, WithReseedInterval(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [16]byte
+	for i := 0; i < 16; i++ {
+		r.Read(buf[:])
+	}
+	if s := r.Stats(); s.Fills != uint64(f.reads) {
+		t.Errorf("got %d reads for %d fills", f.reads, s.Fills)
+	}
+}
+
+func TestReseedDRBG(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opt  Option
+	}{
+		{"ChaCha20", WithChaCha20(0)},
+		{"CTR_DRBG", WithCTRDRBG(nil, 0)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &failingReader{r: &gen{size: 256}}
+			r, err := New(f, 64, tt.opt, WithForkDetection())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.reads != 1 {
+				t.Fatalf("got %d seeds, want 1", f.reads)
+			}
+			if err := r.Reseed(); err != nil {
+				t.Fatal(err)
+			}
+			if f.reads != 2 {
+				t.Errorf("after Reseed got %d seeds, want 2", f.reads)
+			}
+			simulateFork()
+			var buf [16]byte
+			for i := 0; i < 5; i++ {
+				if _, err := r.Read(buf[:]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if f.reads != 3 {
+				t.Errorf("after a fork got %d seeds, want 3", f.reads)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package cachedrander
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// WithRetry causes a failed page load to be retried up to attempts more times
+// before the error is returned to Read.  The first retry is made after backoff
+// and the delay doubles for each subsequent retry, up to a maximum of one
+// second.  Retries are made while holding the fill mutex, so other Reads that
+// need the page wait for the retries to finish rather than each seeing the
+// error.  This suits network or device backed sources with transient errors.
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(r *CachedReader) {
+		r.retries = max(attempts, 0)
+		r.backoff = backoff
+	}
+}
+
+// maxBackoff is the longest delay between retries.
+const maxBackoff = time.Second
+
+// readPage fills data from the source, retrying as configured by WithRetry.
+// It returns the number of bytes read by the final attempt.  r.mu must be held.
+func (r *CachedReader) readPage(data []byte) (int, error) {
+	defer traceLoad().End()
+	delay := r.backoff
+	for i := 0; ; i++ {
+		n, err := io.ReadFull(r.r, data)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = fmt.Errorf("%w: %w", ErrShortSource, err)
+		}
+		if err != nil && r.onError != nil {
+			r.onError(err)
+		}
+		if err == nil || i >= r.retries {
+			return n, err
+		}
+		time.Sleep(delay)
+		delay = min(2*delay, maxBackoff)
+	}
+}
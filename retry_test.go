@@ -0,0 +1,55 @@
+package cachedrander
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// A failingReader fails the first failures Reads and then reads from r.
+type failingReader struct {
+	failures int
+	reads    int
+	r        io.Reader
+}
+
+func (f *failingReader) Read(buf []byte) (int, error) {
+	f.reads++
+	if f.failures > 0 {
+		f.failures--
+		return 0, errors.New("transient")
+	}
+	return f.r.Read(buf)
+}
+
+func TestRetry(t *testing.T) {
+	f := &failingReader{failures: 2, r: &gen{size: 64}}
+	r, err := New(f, 64, WithRetry(2, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.reads != 3 {
+		t.Errorf("got %d reads, want 3", f.reads)
+	}
+	f.failures = 3
+	f.reads = 0
+	r.Max = 64
+	var buf [64]byte
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Read(buf[:]); err == nil {
+		t.Fatal("Read did not return an error")
+	}
+	if f.reads != 3 {
+		t.Errorf("got %d reads, want 3", f.reads)
+	}
+	// The next Read makes the final failure and then succeeds.
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	if buf[0] != 64 {
+		t.Errorf("got data starting at %d, want 64", buf[0])
+	}
+}
@@ -0,0 +1,49 @@
+package cachedrander
+
+import (
+	"encoding/binary"
+	"sync"
+)
+
+// A SequenceSource is a source for tests that never repeats a 16 byte block.
+// Its output is a sequence of 16 byte blocks, each holding its sequence
+// number, starting at 0, as a big-endian uint64 in its last 8 bytes.  The
+// first 8 bytes of each block are zero.
+//
+// Because the bytes set by a UUID's version and variant are either zero or
+// (for all practical sequence numbers) the unused top bits of a sequence
+// number, a test may use a SequenceSource to assert that no UUID is ever
+// generated twice, no matter how the reader is used concurrently.  Only IDs
+// that consume 16 bytes at a time, such as UUIDs, have this guarantee.
+//
+// The zero value is ready to use.  A SequenceSource is safe for concurrent use
+// and must not be used outside of tests.
+type SequenceSource struct {
+	mu  sync.Mutex
+	off uint64 // offset of the next byte in the sequence
+}
+
+// Read fills buf with the next len(buf) bytes of the sequence.  It never
+// returns an error.
+func (s *SequenceSource) Read(buf []byte) (int, error) {
+	s.mu.Lock()
+	off := s.off
+	s.off += uint64(len(buf))
+	s.mu.Unlock()
+
+	var block [16]byte
+	for n := 0; n < len(buf); {
+		binary.BigEndian.PutUint64(block[8:], off/16)
+		c := copy(buf[n:], block[off%16:])
+		n += c
+		off += uint64(c)
+	}
+	return len(buf), nil
+}
+
+// Sequence returns the sequence number of block, a 16 byte block of data read
+// from a SequenceSource, such as a UUID.  Any version and variant bits set in
+// block are ignored.
+func Sequence(block []byte) uint64 {
+	return binary.BigEndian.Uint64(block[8:16]) &^ (3 << 62)
+}
@@ -0,0 +1,63 @@
+package cachedrander
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSequenceSource(t *testing.T) {
+	var s SequenceSource
+	// Reads that do not align with blocks still produce the sequence.
+	buf := make([]byte, 16*5)
+	for i := 0; i < len(buf); i += 7 {
+		s.Read(buf[i:min(i+7, len(buf))])
+	}
+	for i := 0; i < 5; i++ {
+		block := buf[16*i : 16*(i+1)]
+		if got := Sequence(block); got != uint64(i) {
+			t.Errorf("block %d has sequence %d", i, got)
+		}
+		for j, b := range block[:8] {
+			if b != 0 {
+				t.Errorf("block %d byte %d is %#x, want 0", i, j, b)
+			}
+		}
+	}
+}
+
+func TestSequenceUUIDs(t *testing.T) {
+	r, err := New(&SequenceSource{}, 64*16, WithBackgroundFill(0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	const goroutines, each = 8, 1000
+	ids := make(chan uuid.UUID, goroutines*each)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < each; j++ {
+				u, err := uuid.NewRandomFromReader(r)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				ids <- u
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	seen := map[uint64]bool{}
+	for u := range ids {
+		n := Sequence(u[:])
+		if seen[n] {
+			t.Fatalf("UUID %v (sequence %d) was generated twice", u, n)
+		}
+		seen[n] = true
+	}
+}
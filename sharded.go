@@ -0,0 +1,67 @@
+package cachedrander
+
+import (
+	"io"
+	"sync"
+)
+
+// A ShardedReader spreads reads across several independent CachedReaders so
+// that no single atomic counter becomes a point of contention.  Each Read is
+// served by a shard chosen at random using the runtime's per-thread random
+// number generator.
+type ShardedReader struct {
+	shards  []*CachedReader
+	numa    NUMAPolicy // set by NewShardedNUMA
+	perNode int        // shards per node if numa is set
+}
+
+// NewSharded returns a ShardedReader with the specified number of shards, each
+// caching size bytes from r at a time.  Reads from r are serialized, so r need
+// not be safe for concurrent use.  The options are applied to every shard.  An
+// error is returned if filling any of the initial caches returns an error.
+func NewSharded(r io.Reader, size, shards int, opts ...Option) (*ShardedReader, error) {
+	if shards < 1 {
+		shards = 1
+	}
+	if shards > 1 {
+		r = &lockedReader{r: r}
+	}
+	sr := &ShardedReader{shards: make([]*CachedReader, shards)}
+	for i := range sr.shards {
+		cr, err := New(r, size, opts...)
+		if err != nil {
+			sr.Close()
+			return nil, err
+		}
+		sr.shards[i] = cr
+	}
+	return sr, nil
+}
+
+// Read fills buf with cached data from a randomly selected shard, chosen from
+// the shards on the current NUMA node if created by NewShardedNUMA.
+func (s *ShardedReader) Read(buf []byte) (int, error) {
+	return s.shard().Read(buf)
+}
+
+// Close closes all of the shards.  Close always returns nil.
+func (s *ShardedReader) Close() error {
+	for _, cr := range s.shards {
+		if cr != nil {
+			cr.Close()
+		}
+	}
+	return nil
+}
+
+// A lockedReader serializes calls to Read on r.
+type lockedReader struct {
+	mu sync.Mutex
+	r  io.Reader
+}
+
+func (l *lockedReader) Read(buf []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Read(buf)
+}
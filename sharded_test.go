@@ -0,0 +1,47 @@
+package cachedrander
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSharded(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := NewSharded(g, 1<<16, 4, WithMax(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if g.head != 4<<16 {
+		t.Fatalf("initial fill read %d bytes, want %d", g.head, 4<<16)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var buf [8]byte
+			for j := 0; j < 1000; j++ {
+				n, err := r.Read(buf[:])
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if n != len(buf) {
+					t.Errorf("got %d bytes, want %d", n, len(buf))
+					return
+				}
+				// Each read is from a single page that was
+				// filled with sequential bytes.
+				for k := 1; k < n; k++ {
+					if buf[k] != buf[k-1]+1 {
+						t.Errorf("non-sequential read: %v", buf)
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
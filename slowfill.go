@@ -0,0 +1,35 @@
+package cachedrander
+
+import "time"
+
+// FillInfo describes a page load.  It is passed to the function provided
+// with WithSlowFillThreshold.
+type FillInfo struct {
+	Page     uint64        // the generation of the page
+	Size     int           // the size of the page in bytes
+	Duration time.Duration // how long the load took
+	Err      error         // the error returned by the source, if any
+}
+
+// WithSlowFillThreshold causes f to be called with a description of every page
+// load that takes at least d, whether or not it succeeded, so the application
+// can raise an alert or switch to a different source (e.g., with a goroutine
+// that calls Reset) before Reads begin to stall.  d also replaces the default
+// threshold of 100ms used by WithLogger to log slow page loads.  As with
+// WithErrorHandler, f is called while the fill mutex is held, so it must not
+// call methods of the CachedReader, and should return quickly.  A nil f only
+// changes the logging threshold.
+func WithSlowFillThreshold(d time.Duration, f func(FillInfo)) Option {
+	return func(r *CachedReader) {
+		r.slowFill = max(d, 0)
+		r.onSlowFill = f
+	}
+}
+
+// checkSlowFill calls the function provided with WithSlowFillThreshold if the
+// load of generation gen into b, which took d and returned err, was slow.
+func (r *CachedReader) checkSlowFill(gen uint64, b *buffer, d time.Duration, err error) {
+	if r.onSlowFill != nil && d >= r.slowFill {
+		r.onSlowFill(FillInfo{Page: gen, Size: len(b.data), Duration: d, Err: err})
+	}
+}
@@ -0,0 +1,33 @@
+package cachedrander
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSlowFillThreshold(t *testing.T) {
+	var slow []FillInfo
+	record := func(fi FillInfo) { slow = append(slow, fi) }
+	r, err := New(sleepyReader{}, 16, WithSlowFillThreshold(5*time.Millisecond, record))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if len(slow) != 1 {
+		t.Fatalf("got %d slow fills, want 1", len(slow))
+	}
+	fi := slow[0]
+	if fi.Page != 0 || fi.Size != 16 || fi.Duration < 5*time.Millisecond || fi.Err != nil {
+		t.Errorf("got %+v", fi)
+	}
+
+	slow = nil
+	r, err = New(sleepyReader{}, 16, WithSlowFillThreshold(time.Hour, record))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if len(slow) != 0 {
+		t.Errorf("got %d slow fills, want 0", len(slow))
+	}
+}
@@ -0,0 +1,72 @@
+package cachedrander
+
+import (
+	"context"
+	"encoding/binary"
+	"math/rand/v2"
+)
+
+var _ rand.Source = (*CachedReader)(nil)
+
+// Uint64 returns 8 bytes of cached data as a uint64.  Uint64 allows r to be used
+// as a math/rand/v2 Source, for example:
+//
+//	rng := rand.New(r)
+//
+// Since a Source cannot return an error, Uint64 panics if r is closed or the
+// source returns an error.
+func (r *CachedReader) Uint64() uint64 {
+	var b [8]byte
+	if err := r.readFull(context.Background(), b[:]); err != nil {
+		panic(err)
+	}
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// Uint32 returns 4 bytes of cached data as a uint32.  Like Uint64, Uint32
+// panics if r is closed or the source returns an error.
+func (r *CachedReader) Uint32() uint32 {
+	var b [4]byte
+	if err := r.readFull(context.Background(), b[:]); err != nil {
+		panic(err)
+	}
+	return binary.LittleEndian.Uint32(b[:])
+}
+
+// Int63n returns a uniformly distributed non-negative int64 less than n.  It
+// panics if n <= 0.
+func (r *CachedReader) Int63n(n int64) int64 {
+	if n <= 0 {
+		panic("cachedrander: invalid argument to Int63n")
+	}
+	return int64(r.uint64n(uint64(n)))
+}
+
+// Shuffle pseudo-randomizes the order of n elements using the Fisher-Yates
+// algorithm.  swap swaps the elements with indexes i and j.  Shuffle panics if
+// n < 0.
+func (r *CachedReader) Shuffle(n int, swap func(i, j int)) {
+	if n < 0 {
+		panic("cachedrander: invalid argument to Shuffle")
+	}
+	for i := n - 1; i > 0; i-- {
+		j := int(r.uint64n(uint64(i + 1)))
+		swap(i, j)
+	}
+}
+
+// uint64n returns a uniformly distributed value less than n, which must not be
+// 0.  Values less than 2^64 mod n, which would make the smallest results more
+// likely, are rejected before reducing modulo n.
+func (r *CachedReader) uint64n(n uint64) uint64 {
+	if n&(n-1) == 0 {
+		return r.Uint64() & (n - 1)
+	}
+	limit := -n % n // 2^64 mod n
+	for {
+		v := r.Uint64()
+		if v >= limit {
+			return v % n
+		}
+	}
+}
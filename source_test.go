@@ -0,0 +1,89 @@
+package cachedrander
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestUint64(t *testing.T) {
+	g := &gen{size: 17}
+	// The page size is not a multiple of 8 so some values straddle the end of
+	// a page.
+	r, err := New(g, 20, WithMax(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		b := byte(i * 8)
+		want := uint64(b) | uint64(b+1)<<8 | uint64(b+2)<<16 | uint64(b+3)<<24 |
+			uint64(b+4)<<32 | uint64(b+5)<<40 | uint64(b+6)<<48 | uint64(b+7)<<56
+		if got := r.Uint64(); got != want {
+			t.Fatalf("value %d: got %#x, want %#x", i, got, want)
+		}
+	}
+	rng := rand.New(r)
+	if n := rng.IntN(10); n < 0 || n >= 10 {
+		t.Errorf("IntN(10) returned %d", n)
+	}
+}
+
+func TestUint64Closed(t *testing.T) {
+	r, err := New(&gen{size: 17}, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrClosed) {
+			t.Errorf("Uint64 panicked with %v, want %v", err, ErrClosed)
+		}
+	}()
+	r.Uint64()
+}
+
+func TestNumeric(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.Uint32(), uint32(0x03020100); got != want {
+		t.Errorf("Uint32 got %#x, want %#x", got, want)
+	}
+	for _, n := range []int64{1, 2, 3, 7, 8, 1000} {
+		for i := 0; i < 100; i++ {
+			if v := r.Int63n(n); v < 0 || v >= n {
+				t.Fatalf("Int63n(%d) returned %d", n, v)
+			}
+		}
+	}
+	const n = 20
+	var a [n]int
+	for i := range a {
+		a[i] = i
+	}
+	r.Shuffle(n, func(i, j int) { a[i], a[j] = a[j], a[i] })
+	var seen [n]bool
+	for _, v := range a {
+		if seen[v] {
+			t.Fatalf("Shuffle duplicated %d: %v", v, a)
+		}
+		seen[v] = true
+	}
+}
+
+func TestNumericAllocs(t *testing.T) {
+	r, err := New(&gen{size: 17}, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		r.Uint32()
+		r.Uint64()
+		r.Int63n(1000)
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocations, want 0", allocs)
+	}
+}
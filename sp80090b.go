@@ -0,0 +1,136 @@
+package cachedrander
+
+import (
+	"errors"
+	"io"
+	"math"
+)
+
+// ErrHealthTest is wrapped by the FillError returned when the source's output
+// fails the continuous health tests enabled by WithHealthTests.
+var ErrHealthTest = errors.New("cachedrander: source failed continuous health test")
+
+const (
+	// aptWindow is the SP 800-90B adaptive proportion test window size for
+	// non-binary samples.
+	aptWindow = 512
+
+	// healthAlpha is the false positive probability of each test, 2^-20.
+	healthAlpha = 1.0 / (1 << 20)
+)
+
+// WithHealthTests applies the SP 800-90B continuous health tests, the
+// repetition count test and the adaptive proportion test, to every byte read
+// from the source.  minEntropy is the assessed min-entropy of the source in bits
+// per byte, from which the cutoffs are derived as described in SP 800-90B
+// section 4.4.  Values outside the range (0, 8] are treated as 8.
+//
+// When a test fails the page is rejected and read again from the source.  If
+// the new data also fails, loading the page fails with an error wrapping
+// ErrHealthTest.  The tests are only meaningful when applied to the raw output
+// of a noise source, so they are applied to the source before any option,
+// such as WithSHA256Conditioning, that transforms its output.
+func WithHealthTests(minEntropy float64) Option {
+	return func(r *CachedReader) {
+		if minEntropy <= 0 || minEntropy > 8 {
+			minEntropy = 8
+		}
+		r.health = &healthTests{
+			rctCutoff: rctCutoff(minEntropy),
+			aptCutoff: aptCutoff(minEntropy),
+		}
+	}
+}
+
+// rctCutoff returns the repetition count test cutoff for min-entropy h.
+func rctCutoff(h float64) int {
+	return 1 + int(math.Ceil(-math.Log2(healthAlpha)/h))
+}
+
+// aptCutoff returns the adaptive proportion test cutoff for min-entropy h: 1
+// plus the smallest count whose cumulative binomial probability, with
+// aptWindow trials and probability 2^-h, is at least 1-alpha.
+func aptCutoff(h float64) int {
+	p := math.Pow(2, -h)
+	if p == 1 {
+		return aptWindow
+	}
+	pmf := math.Pow(1-p, aptWindow)
+	cdf := pmf
+	k := 0
+	for cdf < 1-healthAlpha && k < aptWindow {
+		pmf *= float64(aptWindow-k) / float64(k+1) * p / (1 - p)
+		k++
+		cdf += pmf
+	}
+	return 1 + k
+}
+
+// healthTests holds the state of the continuous health tests.  It is protected
+// by the CachedReader's mu.
+type healthTests struct {
+	rctCutoff int
+	aptCutoff int
+
+	started bool // a sample has been seen
+	last    byte // the previous sample
+	run     int  // the number of times last has been repeated
+
+	aptFirst byte // the first sample of the window
+	aptCount int  // occurrences of aptFirst in the window
+	aptIndex int  // position in the window
+}
+
+// test runs the tests on data, reporting false if either fails.
+func (t *healthTests) test(data []byte) bool {
+	for _, b := range data {
+		if t.started && b == t.last {
+			if t.run++; t.run >= t.rctCutoff {
+				return false
+			}
+		} else {
+			t.started, t.last, t.run = true, b, 1
+		}
+
+		if t.aptIndex == 0 {
+			t.aptFirst, t.aptCount = b, 1
+		} else if b == t.aptFirst {
+			if t.aptCount++; t.aptCount >= t.aptCutoff {
+				return false
+			}
+		}
+		if t.aptIndex++; t.aptIndex == aptWindow {
+			t.aptIndex = 0
+		}
+	}
+	return true
+}
+
+// reset restarts the tests, as after a failure.
+func (t *healthTests) reset() {
+	*t = healthTests{rctCutoff: t.rctCutoff, aptCutoff: t.aptCutoff}
+}
+
+// A healthReader applies health tests to the data read from r.
+type healthReader struct {
+	r io.Reader
+	t *healthTests
+}
+
+// Read reads from h.r and tests the data.  If the data fails it is discarded
+// and read again.  ErrHealthTest is returned if the new data also fails.
+func (h *healthReader) Read(buf []byte) (int, error) {
+	n, err := h.r.Read(buf)
+	if h.t.test(buf[:n]) {
+		return n, err
+	}
+	h.t.reset()
+	clear(buf[:n])
+	n, err = io.ReadFull(h.r, buf[:n])
+	if h.t.test(buf[:n]) {
+		return n, err
+	}
+	h.t.reset()
+	clear(buf[:n])
+	return 0, ErrHealthTest
+}
@@ -0,0 +1,74 @@
+package cachedrander
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestHealthCutoffs(t *testing.T) {
+	// The values from SP 800-90B sections 4.4.1 and 4.4.2.
+	for _, tt := range []struct {
+		h        float64
+		rct, apt int
+	}{
+		{0.5, 41, 410},
+		{1, 21, 311},
+		{8, 4, 13},
+	} {
+		if got := rctCutoff(tt.h); got != tt.rct {
+			t.Errorf("rctCutoff(%v) got %d, want %d", tt.h, got, tt.rct)
+		}
+		if got := aptCutoff(tt.h); got != tt.apt {
+			t.Errorf("aptCutoff(%v) got %d, want %d", tt.h, got, tt.apt)
+		}
+	}
+}
+
+func TestHealthTests(t *testing.T) {
+	// Sequential bytes pass both tests.
+	r, err := New(&gen{size: 17}, 1024, WithHealthTests(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSequential(t, r)
+
+	// A stuck source fails the repetition count test.
+	_, err = New(bytes.NewReader(make([]byte, 1024)), 64, WithHealthTests(8))
+	if !errors.Is(err, ErrHealthTest) || !errors.Is(err, ErrFillFailed) {
+		t.Errorf("got error %v, want %v", err, ErrHealthTest)
+	}
+
+	// A page that fails is replaced by the next data from the source.
+	bad := make([]byte, 64)
+	good := make([]byte, 64)
+	for i := range good {
+		good[i] = byte(i)
+	}
+	r, err = New(io.MultiReader(bytes.NewReader(bad), bytes.NewReader(good)), 64,
+		WithHealthTests(8), WithMax(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [64]byte
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf[:], good) {
+		t.Errorf("got %v, want %v", buf, good)
+	}
+}
+
+func TestHealthAPT(t *testing.T) {
+	// Every other byte is 0 which fails the adaptive proportion test but
+	// not the repetition count test.
+	h := &healthTests{rctCutoff: rctCutoff(8), aptCutoff: aptCutoff(8)}
+	data := make([]byte, aptWindow)
+	for i := 1; i < len(data); i += 2 {
+		data[i] = byte(i)
+	}
+	if h.test(data) {
+		t.Error("adaptive proportion test passed")
+	}
+}
@@ -0,0 +1,97 @@
+package cachedrander
+
+import "time"
+
+// Stats contains statistics about a CachedReader's use of its cache.
+type Stats struct {
+	// BytesServed is the number of bytes returned by Read.
+	BytesServed uint64
+
+	// Fills is the number of times a page was loaded from the source,
+	// including the initial page and loads that failed.
+	Fills uint64
+
+	// FillTime is the total time spent loading pages from the source.
+	FillTime time.Duration
+
+	// FillDurations is a histogram of how long each page load took.  A
+	// growing tail shows a degrading source before it causes Reads to
+	// stall.  Each duration is also passed to Metrics.Fill (see
+	// WithMetrics) for metrics systems that keep their own histograms.
+	FillDurations Histogram
+
+	// BlockedReads is the number of times a Read found the current page
+	// exhausted and had to wait for the next page.
+	BlockedReads uint64
+
+	// BlockedMax is the longest time a blocked Read waited for its page,
+	// and BlockedDurations is a histogram of how long each one waited.
+	// This is the latency the cache exists to hide from callers.
+	BlockedMax       time.Duration
+	BlockedDurations Histogram
+
+	// WastedBytes is the number of bytes read from the source that were
+	// never served, such as the unread remainder of the pages discarded
+	// by Reseed or Close.
+	WastedBytes uint64
+
+	// Fallbacks is the number of reads served directly from crypto/rand
+	// because a page could not be loaded (see WithFallback).
+	Fallbacks uint64
+
+	// CircuitOpen reports whether the source is currently not being read
+	// and CircuitTrips is the number of times that happened (see
+	// WithCircuitBreaker).
+	CircuitOpen  bool
+	CircuitTrips uint64
+}
+
+// Stats returns the current statistics for r.  It does not wait for a page load
+// in progress.
+func (r *CachedReader) Stats() Stats {
+	r.statMu.Lock()
+	defer r.statMu.Unlock()
+	skipped := r.skipped.Load()
+	s := Stats{
+		BytesServed:   r.served.Load() - skipped,
+		Fills:         r.fills,
+		FillTime:      r.fillTime,
+		FillDurations: r.fillHist,
+		BlockedReads:  r.blocked.Load(),
+		WastedBytes:   r.wasted + skipped,
+		Fallbacks:     r.fallbacks.Load(),
+	}
+	s.BlockedDurations, s.BlockedMax = r.blockedHist.load()
+	if r.breaker != nil {
+		s.CircuitOpen = r.breaker.open()
+		s.CircuitTrips = r.breaker.trips
+	}
+	if p := r.cur.Load(); !p.retired.Load() {
+		s.BytesServed += r.used(p)
+	}
+	return s
+}
+
+// addWasted adds n to the bytes read from the source that were never served.
+func (r *CachedReader) addWasted(n uint64) {
+	r.statMu.Lock()
+	r.wasted += n
+	r.statMu.Unlock()
+}
+
+// used returns the number of bytes of p that have been served.
+func (r *CachedReader) used(p *page) uint64 {
+	return min(p.offset.Load(), p.size)
+}
+
+// retire adds the bytes served from p to r.served, which must only be done
+// once p is no longer the current page.  It returns that number and true the
+// first time it is called for p.
+func (r *CachedReader) retire(p *page) (uint64, bool) {
+	if !p.retired.CompareAndSwap(false, true) {
+		return 0, false
+	}
+	used := r.used(p)
+	r.served.Add(used)
+	return used, true
+}
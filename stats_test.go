@@ -0,0 +1,56 @@
+package cachedrander
+
+import (
+	"io"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [16]byte
+	for i := 0; i < 6; i++ {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := r.Stats()
+	if s.BytesServed != 6*16 {
+		t.Errorf("BytesServed got %d, want %d", s.BytesServed, 6*16)
+	}
+	if s.Fills != 2 {
+		t.Errorf("Fills got %d, want 2", s.Fills)
+	}
+	if s.BlockedReads != 1 {
+		t.Errorf("BlockedReads got %d, want 1", s.BlockedReads)
+	}
+	if n := s.FillDurations.Count(); n != s.Fills {
+		t.Errorf("FillDurations counted %d fills, want %d", n, s.Fills)
+	}
+	if n := s.BlockedDurations.Count(); n != s.BlockedReads {
+		t.Errorf("BlockedDurations counted %d reads, want %d", n, s.BlockedReads)
+	}
+	if s.BlockedMax <= 0 {
+		t.Errorf("BlockedMax got %v", s.BlockedMax)
+	}
+	if s.WastedBytes != 0 {
+		t.Errorf("WastedBytes got %d, want 0", s.WastedBytes)
+	}
+
+	if err := r.Reseed(); err != nil {
+		t.Fatal(err)
+	}
+	s = r.Stats()
+	if s.BytesServed != 6*16 {
+		t.Errorf("BytesServed after Reseed got %d, want %d", s.BytesServed, 6*16)
+	}
+	if s.Fills != 3 {
+		t.Errorf("Fills after Reseed got %d, want 3", s.Fills)
+	}
+	if s.WastedBytes != 32 {
+		t.Errorf("WastedBytes after Reseed got %d, want 32", s.WastedBytes)
+	}
+}
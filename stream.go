@@ -0,0 +1,27 @@
+package cachedrander
+
+import "context"
+
+// Stream returns a channel, with a buffer of buf blocks, that a new goroutine
+// continuously fills with 16 byte blocks of cached data, as returned by Read16.
+// The goroutine exits and the channel is closed when ctx is done or reading
+// from r returns an error, such as after r is closed.  Callers that need the
+// error should call Read16 themselves.
+func (r *CachedReader) Stream(ctx context.Context, buf int) <-chan [16]byte {
+	ch := make(chan [16]byte, max(buf, 0))
+	go func() {
+		defer close(ch)
+		for ctx.Err() == nil {
+			b, err := r.Read16()
+			if err != nil {
+				return
+			}
+			select {
+			case ch <- b:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,56 @@
+package cachedrander
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStream(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := r.Stream(ctx, 4)
+	for i := 0; i < 10; i++ {
+		b := <-ch
+		if want := byte(i * 16); b[0] != want || b[15] != want+15 {
+			t.Fatalf("block %d: got %v, want bytes starting at %d", i, b, want)
+		}
+	}
+	cancel()
+	checkDrained(t, ch)
+}
+
+func TestStreamClose(t *testing.T) {
+	r, err := New(&gen{size: 17}, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := r.Stream(context.Background(), 0)
+	<-ch
+	r.Close()
+	checkDrained(t, ch)
+}
+
+// checkDrained verifies that ch is closed after at most its buffer and one
+// more block are received.
+func checkDrained(t *testing.T, ch <-chan [16]byte) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for n := 0; ; n++ {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+			if n > cap(ch)+1 {
+				t.Fatal("channel was not closed")
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for the channel to close")
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package cachedrander
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+)
+
+// ErrServedTwice is wrapped by the error returned by RunStress when the same
+// data was served more than once.
+var ErrServedTwice = errors.New("cachedrander: data served twice")
+
+// A StressConfig configures RunStress.
+type StressConfig struct {
+	// Reader is the reader to test.  It must be backed by a random source,
+	// such as crypto/rand, not by a SequenceSource or other source whose
+	// output repeats.
+	Reader Rander
+
+	// Goroutines is the number of goroutines reading concurrently.  It
+	// defaults to 8.
+	Goroutines int
+
+	// Reads is the number of reads made by each goroutine.  It defaults to
+	// 1000.
+	Reads int
+
+	// MaxRead is the largest Read to make.  It defaults to 16 and should be
+	// the Max of Reader.
+	MaxRead int
+}
+
+// stressWindow is the size of the windows of served data compared by
+// RunStress.  Two windows of random data are equal with a probability of
+// 2^-64.
+const stressWindow = 8
+
+// RunStress reads from cfg.Reader with cfg.Goroutines goroutines at once,
+// mixing calls to Read (of random sizes up to cfg.MaxRead), Read16, and ReadN,
+// and verifies that no data was served twice and that no call panicked.  It
+// lets applications validate their configuration of a CachedReader, such as its
+// size and options, under concurrency.  RunStress returns the first error
+// returned by the reader, the recovered panic as an error, or an error
+// wrapping ErrServedTwice.
+//
+// Every 8 byte window of each read is remembered, so RunStress uses memory
+// proportional to the total amount of data read.  Reads of fewer than 8 bytes
+// are not checked.
+func RunStress(cfg StressConfig) error {
+	if cfg.Goroutines <= 0 {
+		cfg.Goroutines = 8
+	}
+	if cfg.Reads <= 0 {
+		cfg.Reads = 1000
+	}
+	if cfg.MaxRead <= 0 {
+		cfg.MaxRead = 16
+	}
+	windows := make([][]uint64, cfg.Goroutines)
+	errs := make([]error, cfg.Goroutines)
+	var wg sync.WaitGroup
+	for g := range cfg.Goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if p := recover(); p != nil {
+					errs[g] = fmt.Errorf("cachedrander: panic: %v", p)
+				}
+			}()
+			windows[g], errs[g] = stress(cfg)
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	seen := map[uint64]bool{}
+	for _, ws := range windows {
+		for _, w := range ws {
+			if seen[w] {
+				return fmt.Errorf("%w: %016x", ErrServedTwice, w)
+			}
+			seen[w] = true
+		}
+	}
+	return nil
+}
+
+// stress makes the reads of a single RunStress goroutine and returns the
+// windows of the data it was served.
+func stress(cfg StressConfig) ([]uint64, error) {
+	var windows []uint64
+	buf := make([]byte, max(cfg.MaxRead, 4*16))
+	for range cfg.Reads {
+		var b []byte
+		switch rand.IntN(3) {
+		case 0:
+			n, err := cfg.Reader.Read(buf[:1+rand.IntN(cfg.MaxRead)])
+			if err != nil {
+				return nil, err
+			}
+			b = buf[:n]
+		case 1:
+			u, err := cfg.Reader.Read16()
+			if err != nil {
+				return nil, err
+			}
+			b = buf[:copy(buf, u[:])]
+		case 2:
+			n := 1 + rand.IntN(4)
+			if err := cfg.Reader.ReadN(buf, n); err != nil {
+				return nil, err
+			}
+			b = buf[:n*16]
+		}
+		for i := 0; i+stressWindow <= len(b); i++ {
+			windows = append(windows, binary.LittleEndian.Uint64(b[i:]))
+		}
+	}
+	return windows, nil
+}
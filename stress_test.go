@@ -0,0 +1,47 @@
+package cachedrander
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// zeroReader returns zeros.
+type zeroReader struct{}
+
+func (zeroReader) Read(buf []byte) (int, error) {
+	clear(buf)
+	return len(buf), nil
+}
+
+// panicRander panics on every call.
+type panicRander struct{ Passthrough }
+
+func (panicRander) Read16() ([16]byte, error) { panic("boom") }
+
+func TestRunStress(t *testing.T) {
+	r, err := NewUUIDReader(64, WithBackgroundFill(0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err := RunStress(StressConfig{Reader: r}); err != nil {
+		t.Error(err)
+	}
+
+	err = RunStress(StressConfig{Reader: Passthrough{R: zeroReader{}}, Goroutines: 2, Reads: 10})
+	if !errors.Is(err, ErrServedTwice) {
+		t.Errorf("got error %v, want %v", err, ErrServedTwice)
+	}
+
+	errTest := errors.New("test")
+	err = RunStress(StressConfig{Reader: FailingRander{Err: errTest}})
+	if !errors.Is(err, errTest) {
+		t.Errorf("got error %v, want %v", err, errTest)
+	}
+
+	err = RunStress(StressConfig{Reader: panicRander{}, Reads: 100})
+	if err == nil || !strings.Contains(err.Error(), "panic: boom") {
+		t.Errorf("got error %v, want a panic", err)
+	}
+}
@@ -0,0 +1,77 @@
+package cachedrander
+
+import "errors"
+
+// ErrDegenerateSource is wrapped by the FillError returned when
+// WithSanityCheck is used and a page consists of a single repeated byte, such
+// as all zeros.
+var ErrDegenerateSource = errors.New("cachedrander: source returned a single repeated byte")
+
+// ErrStuckSource is wrapped by the FillError returned when WithStuckDetection
+// is used and a newly loaded page is identical, or nearly so, to the previous
+// page.
+var ErrStuckSource = errors.New("cachedrander: source repeated its output")
+
+// WithStuckDetection compares each newly loaded page with the page before it
+// and fails the load with an error wrapping ErrStuckSource if they are
+// suspiciously similar.  Identical or nearly identical pages are a classic
+// symptom of a broken hardware random number generator or a misconfigured test
+// double.  Pages are similar if more than an eighth of the bytes (and at
+// least 8) of their first kilobyte match byte for byte; random pages are
+// expected to match 1 byte in 256.  A copy of the first kilobyte of the last
+// page loaded is kept for the comparison, taken before the page is modified by
+// options such as WithEncryptedPages or WithZeroizeOnRead, and is zeroed when
+// the cached data is discarded.
+func WithStuckDetection() Option {
+	return func(r *CachedReader) {
+		r.stuck = true
+	}
+}
+
+// WithSanityCheck fails the load of any page, including the first page loaded
+// by New, that consists of a single repeated byte, such as all zeros, with an
+// error wrapping ErrDegenerateSource.  This catches sources that succeed while
+// returning nothing useful.  The check is a single pass over the page and stops
+// at the first byte that differs.
+func WithSanityCheck() Option {
+	return func(r *CachedReader) {
+		r.sanity = true
+	}
+}
+
+// checkSanity returns ErrDegenerateSource if data is a single repeated byte.
+func checkSanity(data []byte) error {
+	for _, b := range data {
+		if b != data[0] {
+			return nil
+		}
+	}
+	return ErrDegenerateSource
+}
+
+// stuckSample is the number of bytes at the start of each page compared by
+// WithStuckDetection.
+const stuckSample = 1024
+
+// checkStuck returns ErrStuckSource if data, just loaded for generation gen,
+// is too similar to generation gen-1, if it was the last page loaded.
+// Otherwise it keeps the start of data to compare with generation gen+1.  r.mu
+// must be held.
+func (r *CachedReader) checkStuck(gen uint64, data []byte) error {
+	data = data[:min(len(data), stuckSample)]
+	if prev := r.stuckPrev; gen > 0 && r.stuckGen == gen-1 && len(prev) > 0 {
+		n := min(len(data), len(prev))
+		same := 0
+		for i, b := range data[:n] {
+			if b == prev[i] {
+				same++
+			}
+		}
+		if same > max(n/8, 8) || same == n {
+			return ErrStuckSource
+		}
+	}
+	r.stuckPrev = append(r.stuckPrev[:0], data...)
+	r.stuckGen = gen
+	return nil
+}
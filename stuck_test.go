@@ -0,0 +1,72 @@
+package cachedrander
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestStuckDetection(t *testing.T) {
+	// gen repeats every 256 bytes, so with 256 byte pages every page is
+	// the same.
+	r, err := New(&gen{size: 256}, 256, WithStuckDetection(), WithMax(256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [256]byte
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.Read(buf[:])
+	if !errors.Is(err, ErrStuckSource) || !errors.Is(err, ErrFillFailed) {
+		t.Errorf("got error %v, want %v", err, ErrStuckSource)
+	}
+
+	// Pages that differ pass.
+	r, err = New(&gen{size: 17}, 96, WithStuckDetection())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSequential(t, r)
+}
+
+func TestSanityCheck(t *testing.T) {
+	// The flakyReader returns all 1s.
+	_, err := New(&flakyReader{}, 64, WithSanityCheck())
+	if !errors.Is(err, ErrDegenerateSource) || !errors.Is(err, ErrFillFailed) {
+		t.Errorf("got error %v, want %v", err, ErrDegenerateSource)
+	}
+	_, err = New(bytes.NewReader(make([]byte, 64)), 64, WithSanityCheck())
+	if !errors.Is(err, ErrDegenerateSource) {
+		t.Errorf("got error %v, want %v", err, ErrDegenerateSource)
+	}
+	r, err := New(&gen{size: 17}, 64, WithSanityCheck())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSequential(t, r)
+}
+
+func TestStuckDetectionModified(t *testing.T) {
+	// The previous page is compared as it was loaded, not as it is after
+	// being encrypted or zeroed.
+	for _, tt := range []struct {
+		name string
+		opt  Option
+	}{
+		{"encrypted", WithEncryptedPages()},
+		{"zeroized", WithZeroizeOnRead()},
+	} {
+		r, err := New(&gen{size: 256}, 256, WithStuckDetection(), WithMax(256), tt.opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf [256]byte
+		if _, err := r.Read(buf[:]); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.Read(buf[:]); !errors.Is(err, ErrStuckSource) {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, ErrStuckSource)
+		}
+	}
+}
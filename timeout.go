@@ -0,0 +1,38 @@
+package cachedrander
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrFillTimeout is returned by Read when WithFillTimeout is used and the next
+// page was not loaded in time.
+var ErrFillTimeout = errors.New("cachedrander: timed out waiting for the source")
+
+// WithFillTimeout causes a Read that must wait for a page to be loaded to fail
+// with ErrFillTimeout if the page is not available within d.  This prevents a
+// hung source, such as a network file system, from stalling every caller.  The
+// load itself is not canceled and subsequent Reads succeed once it completes.
+// Values of d less than or equal to 0 disable the timeout.
+func WithFillTimeout(d time.Duration) Option {
+	return func(r *CachedReader) {
+		r.fillTimeout = max(d, 0)
+	}
+}
+
+// waitTimeout calls wait, returning early if ctx is done or r.fillTimeout
+// expires first.
+func (r *CachedReader) waitTimeout(ctx context.Context, p *page) error {
+	if r.swap(p) {
+		// No need to start the timer.
+		return nil
+	}
+	tctx, cancel := context.WithTimeout(ctx, r.fillTimeout)
+	defer cancel()
+	err := r.wait(tctx, p)
+	if err != nil && ctx.Err() == nil && tctx.Err() != nil {
+		return ErrFillTimeout
+	}
+	return err
+}
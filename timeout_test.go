@@ -0,0 +1,58 @@
+package cachedrander
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestFillTimeout(t *testing.T) {
+	s := &slowReader{release: make(chan struct{})}
+	close(s.release)
+	r, err := New(s, 16, WithFillTimeout(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.release = make(chan struct{})
+	var buf [16]byte
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Read(buf[:]); !errors.Is(err, ErrFillTimeout) {
+		t.Fatalf("got error %v, want %v", err, ErrFillTimeout)
+	}
+	// The load was not canceled, once it finishes Reads succeed again.
+	close(s.release)
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFillTimeoutGoroutines(t *testing.T) {
+	s := &slowReader{release: make(chan struct{})}
+	close(s.release)
+	r, err := New(s, 16, WithFillTimeout(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.release = make(chan struct{})
+	var buf [16]byte
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		if _, err := r.Read(buf[:]); !errors.Is(err, ErrFillTimeout) {
+			t.Fatalf("got error %v, want %v", err, ErrFillTimeout)
+		}
+	}
+	// Only the one hung load may be left running.
+	if n := runtime.NumGoroutine(); n > before+1 {
+		t.Errorf("%d goroutines left running after timed out Reads, want at most 1", n-before)
+	}
+	close(s.release)
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,56 @@
+package cachedrander
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+)
+
+// Character sets for use with StringWithCharset.
+const (
+	Hex          = "0123456789abcdef"
+	Base64URL    = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	Alphanumeric = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+// ErrInvalidCharset is returned by StringWithCharset when the character set is
+// empty or has more than 256 characters.
+var ErrInvalidCharset = errors.New("cachedrander: character set must have 1 to 256 characters")
+
+// Token returns n bytes of cached data encoded with unpadded base64url, making
+// it suitable for session tokens, URLs and file names.
+func (r *CachedReader) Token(n int) (string, error) {
+	buf := make([]byte, n)
+	if err := r.readChunks(context.Background(), buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// StringWithCharset returns a string of n characters, each chosen uniformly
+// from charset.  charset is treated as a sequence of bytes, not runes.  Cached
+// bytes that would bias the result are discarded.
+func (r *CachedReader) StringWithCharset(n int, charset string) (string, error) {
+	if len(charset) == 0 || len(charset) > 256 {
+		return "", ErrInvalidCharset
+	}
+	// Bytes at or above limit are rejected so every character is equally
+	// likely.
+	limit := 256 - 256%len(charset)
+	out := make([]byte, 0, n)
+	var buf [64]byte
+	for len(out) < n {
+		// Read a little extra to account for rejected bytes.
+		need := n - len(out)
+		chunk := buf[:min(need+need/4+1, len(buf), int(r.size.Load()))]
+		if err := r.readFull(context.Background(), chunk); err != nil {
+			return "", err
+		}
+		for _, b := range chunk {
+			if int(b) < limit && len(out) < n {
+				out = append(out, charset[int(b)%len(charset)])
+			}
+		}
+	}
+	return string(out), nil
+}
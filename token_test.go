@@ -0,0 +1,72 @@
+package cachedrander
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestToken(t *testing.T) {
+	r, err := New(&gen{size: 17}, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 0x00 0x01 0x02 encodes as AAEC.
+	tok, err := r.Token(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok != "AAEC" {
+		t.Errorf("got %q, want %q", tok, "AAEC")
+	}
+	// Tokens may be larger than a page.
+	tok, err = r.Token(200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tok) != 267 {
+		t.Errorf("got %d characters, want 267", len(tok))
+	}
+}
+
+func TestStringWithCharset(t *testing.T) {
+	r, err := New(&gen{size: 17}, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, charset := range []string{Hex, Base64URL, Alphanumeric, "x"} {
+		for _, n := range []int{0, 1, 10, 100} {
+			s, err := r.StringWithCharset(n, charset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(s) != n {
+				t.Errorf("got %d characters, want %d", len(s), n)
+			}
+			for _, c := range s {
+				if !strings.ContainsRune(charset, c) {
+					t.Fatalf("%q is not from charset %q", s, charset)
+				}
+			}
+		}
+	}
+	// With 62 characters, bytes 248 through 255 are rejected.  gen
+	// returns the bytes in order so every character appears exactly 4
+	// times in the first 248.
+	r, err = New(&gen{size: 17}, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := r.StringWithCharset(248, Alphanumeric)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range Alphanumeric {
+		if n := strings.Count(s, string(c)); n != 4 {
+			t.Errorf("%c appears %d times, want 4", c, n)
+		}
+	}
+	if _, err := r.StringWithCharset(1, ""); !errors.Is(err, ErrInvalidCharset) {
+		t.Errorf("empty charset: got error %v, want %v", err, ErrInvalidCharset)
+	}
+}
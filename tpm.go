@@ -0,0 +1,48 @@
+package cachedrander
+
+import "errors"
+
+// defaultTPMChunk is the size of a SHA-256 digest, the largest request every
+// TPM 2.0 is required to honor.
+const defaultTPMChunk = 32
+
+// errTPMShort is returned when the TPM returns no data.
+var errTPMShort = errors.New("cachedrander: TPM GetRandom returned no data")
+
+// A TPMReader is a source that reads from a TPM 2.0 using its GetRandom
+// command.  A TPM only returns up to one digest's worth of data per command,
+// so Read breaks large requests into chunks.
+//
+// To avoid depending on a particular TPM library, GetRandom is a function.
+// With github.com/google/go-tpm it is typically:
+//
+//	func(n uint16) ([]byte, error) { return tpm2.GetRandom(rwc, n) }
+type TPMReader struct {
+	// GetRandom issues a TPM2_GetRandom command for n bytes.  It may
+	// return fewer than n bytes.
+	GetRandom func(n uint16) ([]byte, error)
+
+	// ChunkSize is the most data requested per command.  It defaults to
+	// 32 bytes.
+	ChunkSize int
+}
+
+// Read fills buf by issuing as many GetRandom commands as required.
+func (t *TPMReader) Read(buf []byte) (int, error) {
+	chunk := t.ChunkSize
+	if chunk <= 0 {
+		chunk = defaultTPMChunk
+	}
+	n := 0
+	for n < len(buf) {
+		b, err := t.GetRandom(uint16(min(chunk, len(buf)-n, 0xffff)))
+		if err != nil {
+			return n, err
+		}
+		if len(b) == 0 {
+			return n, errTPMShort
+		}
+		n += copy(buf[n:], b)
+	}
+	return n, nil
+}
@@ -0,0 +1,32 @@
+package cachedrander
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTPMReader(t *testing.T) {
+	var calls int
+	g := &gen{size: 1 << 20}
+	tpm := &TPMReader{
+		GetRandom: func(n uint16) ([]byte, error) {
+			calls++
+			if n > 32 {
+				return nil, errors.New("request too large")
+			}
+			// Return less than asked for, as a TPM may.
+			b := make([]byte, n/2+1)
+			g.Read(b)
+			return b, nil
+		},
+	}
+	r, err := New(tpm, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Max = 8
+	checkSequential(t, r)
+	if calls < 256/17 {
+		t.Errorf("only %d calls to GetRandom", calls)
+	}
+}
@@ -0,0 +1,34 @@
+package cachedrander
+
+import (
+	"context"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// Page loads are annotated so their cost is attributed to this package in
+// execution traces and CPU profiles:
+//
+//   - Each read of a page from the source is a trace region named
+//     "cachedrander.load".
+//   - The goroutines started by a CachedReader, such as the background
+//     filler, carry the pprof label "cachedrander" set to their role, such
+//     as "filler".
+//
+// Page loads made by a Read's own goroutine are not labeled, as that would
+// replace any labels set by the caller.
+
+// loadRegion is the name of the trace region for reading a page.
+const loadRegion = "cachedrander.load"
+
+// labelGoroutine sets the pprof labels of the calling goroutine, which must
+// have been started by this package, to identify its role.
+func labelGoroutine(role string) {
+	pprof.SetGoroutineLabels(pprof.WithLabels(context.Background(), pprof.Labels("cachedrander", role)))
+}
+
+// traceLoad starts the trace region for reading a page.  The caller must call
+// End on the returned region.
+func traceLoad() *trace.Region {
+	return trace.StartRegion(context.Background(), loadRegion)
+}
@@ -0,0 +1,48 @@
+package cachedrander
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"runtime/trace"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFillerLabels(t *testing.T) {
+	r, err := New(&gen{size: 256}, 64, WithBackgroundFill(0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	deadline := time.Now().Add(time.Second)
+	for {
+		var buf bytes.Buffer
+		pprof.Lookup("goroutine").WriteTo(&buf, 1)
+		if strings.Contains(buf.String(), `labels: {"cachedrander":"filler"}`) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("filler goroutine is not labeled")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLoadRegion(t *testing.T) {
+	if trace.IsEnabled() {
+		t.Skip("tracing is already enabled")
+	}
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Fatal(err)
+	}
+	_, err := New(&gen{size: 256}, 64)
+	trace.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(loadRegion)) {
+		t.Errorf("trace does not contain the %s region", loadRegion)
+	}
+}
@@ -0,0 +1,36 @@
+package cachedrander
+
+// TryRead is like Read but never waits for a page to be loaded from the
+// source.  It reports false, having read nothing, if the current page is
+// exhausted and no standby page is loaded, or if r is closed or has failed.
+// Latency sensitive callers can then fall back to another source of data.
+// Like Read, TryRead reads at most Max bytes.
+func (r *CachedReader) TryRead(buf []byte) (int, bool) {
+	if m := r.maxLen(); len(buf) > m {
+		buf = buf[:m]
+	}
+	blen := uint64(len(buf))
+	if blen == 0 {
+		return 0, true
+	}
+	if err := r.checkFork(); err != nil {
+		return 0, false
+	}
+	for {
+		p := r.cur.Load()
+		end := p.offset.Add(blen)
+		if start := end - blen; start < p.size {
+			n, ok := r.copyAt(buf, p, start)
+			if !ok {
+				// The page was reloaded out from under us.
+				r.skipped.Add(blen)
+				continue
+			}
+			r.checkWatermark(p, start, end)
+			return n, true
+		}
+		if !r.swap(p) {
+			return 0, false
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package cachedrander
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTryRead(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 32, WithMax(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [16]byte
+	for i := 0; i < 2; i++ {
+		n, ok := r.TryRead(buf[:])
+		if !ok || n != 16 || buf[0] != byte(i*16) {
+			t.Fatalf("read %d: got %d, %v, %v", i, n, ok, buf)
+		}
+	}
+	// Without a background filler the next page is never loaded ahead.
+	if n, ok := r.TryRead(buf[:]); ok {
+		t.Fatalf("got %d bytes, want a failed read", n)
+	}
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	if buf[0] != 32 {
+		t.Errorf("got data starting at %d, want 32", buf[0])
+	}
+}
+
+func TestTryReadStandby(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 32, WithWarmStandby())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var buf [16]byte
+	for i := 0; i < 4; i++ {
+		deadline := time.Now().Add(time.Second)
+		for {
+			n, ok := r.TryRead(buf[:])
+			if ok {
+				if n != 16 || buf[0] != byte(i*16) {
+					t.Fatalf("read %d: got %d bytes starting at %d", i, n, buf[0])
+				}
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("read %d: standby page never loaded", i)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if s := r.Stats(); s.BlockedReads != 0 {
+		t.Errorf("got %d blocked reads, want 0", s.BlockedReads)
+	}
+}
@@ -0,0 +1,83 @@
+// Package ulidentropy adapts a cachedrander.CachedReader for use as the
+// entropy source of github.com/oklog/ulid without depending on that package.
+//
+// A CachedReader can be passed to ulid.New directly as long as its Max is at
+// least 10.  Monotonic wraps a CachedReader so that ULIDs generated within the
+// same millisecond are strictly increasing, like ulid.Monotonic, but it is also
+// safe for concurrent use.
+package ulidentropy
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"sync"
+)
+
+// ErrMonotonicOverflow is returned by MonotonicRead when incrementing the
+// previous entropy would overflow its 80 bits.
+var ErrMonotonicOverflow = errors.New("ulidentropy: monotonic entropy overflow")
+
+// A Monotonic is an entropy source that implements ulid.MonotonicReader.
+type Monotonic struct {
+	r   io.Reader
+	inc uint64
+
+	mu     sync.Mutex
+	ms     uint64
+	hi     uint16 // high 16 bits of the last entropy
+	lo     uint64 // low 64 bits of the last entropy
+	primed bool
+}
+
+// NewMonotonic returns a Monotonic that reads entropy from r, typically a
+// cachedrander.CachedReader.  Within a millisecond the entropy is incremented
+// by a random amount in the range [1, inc].  An inc of 0 is treated as
+// math.MaxUint32, the same as ulid.Monotonic.
+func NewMonotonic(r io.Reader, inc uint64) *Monotonic {
+	if inc == 0 {
+		inc = math.MaxUint32
+	}
+	return &Monotonic{r: r, inc: inc}
+}
+
+// Read reads len(p) bytes of entropy from the underlying reader.
+func (m *Monotonic) Read(p []byte) (int, error) {
+	return io.ReadFull(m.r, p)
+}
+
+// MonotonicRead fills p, which must be 10 bytes long, with entropy for a ULID
+// with the timestamp ms.  If ms is the same as the previous call the entropy is
+// the previous entropy incremented by a random amount.
+func (m *Monotonic) MonotonicRead(ms uint64, p []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.primed || ms != m.ms {
+		if _, err := io.ReadFull(m.r, p); err != nil {
+			return err
+		}
+		m.hi = binary.BigEndian.Uint16(p[:2])
+		m.lo = binary.BigEndian.Uint64(p[2:])
+		m.ms = ms
+		m.primed = true
+		return nil
+	}
+	var b [8]byte
+	if _, err := io.ReadFull(m.r, b[:]); err != nil {
+		return err
+	}
+	inc := 1 + binary.BigEndian.Uint64(b[:])%m.inc
+	lo := m.lo + inc
+	hi := m.hi
+	if lo < m.lo {
+		if hi == math.MaxUint16 {
+			return ErrMonotonicOverflow
+		}
+		hi++
+	}
+	m.hi, m.lo = hi, lo
+	binary.BigEndian.PutUint16(p[:2], hi)
+	binary.BigEndian.PutUint64(p[2:], lo)
+	return nil
+}
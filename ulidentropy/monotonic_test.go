@@ -0,0 +1,54 @@
+package ulidentropy
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/pborman/cachedrander"
+)
+
+func TestMonotonic(t *testing.T) {
+	r, err := cachedrander.NewUUIDReader(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMonotonic(r, 0)
+
+	var mu sync.Mutex
+	var seen [][]byte
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				p := make([]byte, 10)
+				mu.Lock()
+				if err := m.MonotonicRead(42, p); err != nil {
+					t.Error(err)
+				}
+				seen = append(seen, p)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	for i := 1; i < len(seen); i++ {
+		if bytes.Compare(seen[i-1], seen[i]) >= 0 {
+			t.Fatalf("entropy %d (%x) is not after %x", i, seen[i], seen[i-1])
+		}
+	}
+}
+
+func TestMonotonicOverflow(t *testing.T) {
+	ones := bytes.NewReader(bytes.Repeat([]byte{0xff}, 18))
+	m := NewMonotonic(ones, 1)
+	p := make([]byte, 10)
+	if err := m.MonotonicRead(1, p); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.MonotonicRead(1, p); err != ErrMonotonicOverflow {
+		t.Fatalf("got error %v, want %v", err, ErrMonotonicOverflow)
+	}
+}
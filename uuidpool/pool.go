@@ -0,0 +1,131 @@
+// Package uuidpool generates github.com/google/uuid UUIDs directly from a
+// cachedrander.CachedReader.  Unlike calling uuid.SetRand, using a Pool does
+// not change the global state of the uuid package.
+package uuidpool
+
+import (
+	"encoding/binary"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pborman/cachedrander"
+)
+
+// A Pool generates UUIDs from the data cached by a CachedReader.
+type Pool struct {
+	r  *cachedrander.CachedReader
+	v7 atomic.Uint64 // last version 7 timestamp<<12 | counter
+}
+
+// New returns a Pool that caches n UUIDs' worth of data from crypto/rand at a
+// time.  See cachedrander.NewUUIDReader for guidance on choosing n.
+func New(n int, opts ...cachedrander.Option) (*Pool, error) {
+	r, err := cachedrander.NewUUIDReader(n, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Pool{r: r}, nil
+}
+
+// NewFromReader returns a Pool that generates UUIDs from r.
+func NewFromReader(r *cachedrander.CachedReader) *Pool {
+	return &Pool{r: r}
+}
+
+// NewPreformed is like New but the CachedReader is created with
+// cachedrander.WithPreformedUUIDs, so the version and variant bits of version 4
+// UUIDs are set once per page rather than once per UUID.  Pools made this way
+// can still generate version 7 UUIDs.
+func NewPreformed(n int, opts ...cachedrander.Option) (*Pool, error) {
+	return New(n, append(opts, cachedrander.WithPreformedUUIDs())...)
+}
+
+// Reader returns the CachedReader used by p.
+func (p *Pool) Reader() *cachedrander.CachedReader {
+	return p.r
+}
+
+// NewV4 returns a new random (version 4) UUID.
+func (p *Pool) NewV4() (uuid.UUID, error) {
+	b, err := p.r.Read16()
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if p.r.PreformedUUIDs() {
+		return uuid.UUID(b), nil
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // Version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // Variant is 10
+	return uuid.UUID(b), nil
+}
+
+// NewV7 returns a new time-ordered (version 7) UUID.  The UUID holds the
+// current Unix time in milliseconds followed by a 12 bit counter and 62 random
+// bits.  The counter starts at a random value each millisecond and is
+// incremented for each UUID generated within that millisecond, so UUIDs
+// returned by p are strictly increasing.  Should the counter overflow the
+// timestamp is advanced by a millisecond.
+func (p *Pool) NewV7() (uuid.UUID, error) {
+	b, err := p.r.Read16()
+	if err != nil {
+		return uuid.Nil, err
+	}
+	ms, seq := p.next(uint64(b[6])<<8 | uint64(b[7]))
+	var t [8]byte
+	binary.BigEndian.PutUint64(t[:], ms)
+	copy(b[:6], t[2:])
+	b[6] = 0x70 | byte(seq>>8) // Version 7
+	b[7] = byte(seq)
+	b[8] = (b[8] & 0x3f) | 0x80 // Variant is 10
+	return uuid.UUID(b), nil
+}
+
+// next returns the timestamp and counter for the next version 7 UUID.  The
+// counter is set from the low 11 bits of seed when the timestamp changes,
+// leaving room for the counter to be incremented.
+func (p *Pool) next(seed uint64) (ms, seq uint64) {
+	for {
+		last := p.v7.Load()
+		now := uint64(time.Now().UnixMilli())
+		v := last + 1
+		if now > last>>12 {
+			v = now<<12 | seed&0x7ff
+		}
+		if p.v7.CompareAndSwap(last, v) {
+			return v >> 12, v & 0xfff
+		}
+	}
+}
+
+// hexPairs holds the two lowercase hex digits of each byte value.
+var hexPairs = func() (t [256][2]byte) {
+	const digits = "0123456789abcdef"
+	for i := range t {
+		t[i] = [2]byte{digits[i>>4], digits[i&0xf]}
+	}
+	return t
+}()
+
+// NewString returns a new random (version 4) UUID in its canonical 36
+// character form, xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx.  It is equivalent to
+// calling String on the result of NewV4 but is faster, encoding each byte with
+// a single table lookup.
+func (p *Pool) NewString() (string, error) {
+	u, err := p.NewV4()
+	if err != nil {
+		return "", err
+	}
+	var s [36]byte
+	j := 0
+	for i, b := range u {
+		switch i {
+		case 4, 6, 8, 10:
+			s[j] = '-'
+			j++
+		}
+		s[j], s[j+1] = hexPairs[b][0], hexPairs[b][1]
+		j += 2
+	}
+	return string(s[:]), nil
+}
@@ -0,0 +1,114 @@
+package uuidpool
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pborman/cachedrander"
+)
+
+func TestNewV4(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		new  func(int, ...cachedrander.Option) (*Pool, error)
+	}{
+		{"New", New},
+		{"NewPreformed", NewPreformed},
+	} {
+		p, err := tt.new(100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen := map[uuid.UUID]bool{}
+		for i := 0; i < 1000; i++ {
+			u, err := p.NewV4()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v := u.Version(); v != 4 {
+				t.Fatalf("%s: %v: got version %d, want 4", tt.name, u, v)
+			}
+			if v := u.Variant(); v != uuid.RFC4122 {
+				t.Fatalf("%s: %v: got variant %v, want %v", tt.name, u, v, uuid.RFC4122)
+			}
+			if seen[u] {
+				t.Fatalf("%s: %v: duplicate UUID", tt.name, u)
+			}
+			seen[u] = true
+		}
+	}
+}
+
+func BenchmarkNewV4(b *testing.B) {
+	p, err := New(1000)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		p.NewV4()
+	}
+}
+
+func TestNewV7(t *testing.T) {
+	p, err := New(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now().UnixMilli()
+	var last uuid.UUID
+	for i := 0; i < 10000; i++ {
+		u, err := p.NewV7()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := u.Version(); v != 7 {
+			t.Fatalf("%v: got version %d, want 7", u, v)
+		}
+		if v := u.Variant(); v != uuid.RFC4122 {
+			t.Fatalf("%v: got variant %v, want %v", u, v, uuid.RFC4122)
+		}
+		if bytes.Compare(u[:], last[:]) <= 0 {
+			t.Fatalf("%v is not after %v", u, last)
+		}
+		last = u
+	}
+	sec, _ := last.Time().UnixTime()
+	if sec < start/1000 {
+		t.Errorf("got time %d, want at least %d", sec, start/1000)
+	}
+}
+
+func TestNewString(t *testing.T) {
+	p, err := New(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		s, err := p.NewString()
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := uuid.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.String() != s {
+			t.Fatalf("got %q, want %q", s, u.String())
+		}
+		if v := u.Version(); v != 4 {
+			t.Fatalf("%v: got version %d, want 4", u, v)
+		}
+	}
+}
+
+func BenchmarkNewString(b *testing.B) {
+	p, err := New(1000)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		p.NewString()
+	}
+}
@@ -0,0 +1,64 @@
+package cachedrander
+
+import (
+	"bytes"
+	"os"
+	"time"
+)
+
+// defaultVMGenIDInterval is the default polling interval for
+// WithVMGenerationID.
+const defaultVMGenIDInterval = time.Second
+
+// WithVMGenerationID causes the CachedReader to reseed itself whenever the
+// virtual machine generation ID changes, so cloned or restored virtual machines
+// do not serve the same data from the same cached pages.  A goroutine calls
+// read every interval (default 1 second) to obtain the current ID.  The
+// goroutine is stopped by calling Close.
+//
+// This package does not detect clones by itself: the caller must supply read,
+// which depends on the platform and hypervisor.  Neither Linux, whose vmgenid
+// driver only reseeds the kernel's random number generator, nor Windows
+// exposes the ID as a file, so VMGenIDFile is only useful when the ID is made
+// available as one by other means, such as a hypervisor agent.  Errors returned
+// by read are ignored.
+func WithVMGenerationID(read func() ([]byte, error), interval time.Duration) Option {
+	return func(r *CachedReader) {
+		if interval <= 0 {
+			interval = defaultVMGenIDInterval
+		}
+		r.vmgenID = read
+		r.vmgenInterval = interval
+	}
+}
+
+// VMGenIDFile returns a function, for use with WithVMGenerationID, that returns
+// the contents of the file path.
+func VMGenIDFile(path string) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		return os.ReadFile(path)
+	}
+}
+
+// watchVMGenID reseeds r each time the virtual machine generation ID changes
+// from last until r is closed.
+func (r *CachedReader) watchVMGenID(last []byte) {
+	labelGoroutine("vmgenid")
+	t := time.NewTicker(r.vmgenInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-t.C:
+		}
+		id, err := r.vmgenID()
+		if err != nil || bytes.Equal(id, last) {
+			continue
+		}
+		last = id
+		// If the reseed fails the discarded pages will be
+		// reloaded by the next Read.
+		r.Reseed()
+	}
+}
@@ -0,0 +1,59 @@
+package cachedrander
+
+import (
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestVMGenerationID(t *testing.T) {
+	var id atomic.Int32
+	g := &gen{size: 17}
+	r, err := New(g, 64, WithVMGenerationID(func() ([]byte, error) {
+		return []byte{byte(id.Load())}, nil
+	}, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	id.Store(1)
+	for i := 0; i < 1000; i++ {
+		if r.Stats().Fills > 1 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("generation ID change did not cause a reseed")
+}
+
+// A countingReader counts the Reads of r.
+type countingReader struct {
+	r     io.Reader
+	reads atomic.Int32
+}
+
+func (c *countingReader) Read(buf []byte) (int, error) {
+	c.reads.Add(1)
+	return c.r.Read(buf)
+}
+
+func TestVMGenerationIDDRBG(t *testing.T) {
+	var id atomic.Int32
+	c := &countingReader{r: &gen{size: 256}}
+	r, err := New(c, 64, WithChaCha20(0), WithVMGenerationID(func() ([]byte, error) {
+		return []byte{byte(id.Load())}, nil
+	}, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	id.Store(1)
+	for i := 0; i < 1000; i++ {
+		if c.reads.Load() > 1 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("generation ID change did not reseed the DRBG")
+}
@@ -0,0 +1,31 @@
+package cachedrander
+
+import (
+	"context"
+	"io"
+)
+
+// WriteN writes n bytes of cached data to w, one page's worth (at most 32KiB)
+// at a time.  It returns the number of bytes written and the first error
+// encountered.  WriteN is intended for exporting large amounts of data, such
+// as to a file or a statistical test suite.  CachedReader does not implement
+// io.WriterTo as its data never ends.
+func (r *CachedReader) WriteN(w io.Writer, n int64) (int64, error) {
+	buf := make([]byte, min(uint64(n), r.size.Load(), 32<<10))
+	var written int64
+	for written < n {
+		chunk := buf[:min(int64(len(buf)), n-written)]
+		if err := r.readChunks(context.Background(), chunk); err != nil {
+			return written, err
+		}
+		nw, err := w.Write(chunk)
+		written += int64(nw)
+		if err != nil {
+			return written, err
+		}
+		if nw != len(chunk) {
+			return written, io.ErrShortWrite
+		}
+	}
+	return written, nil
+}
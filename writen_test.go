@@ -0,0 +1,30 @@
+package cachedrander
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteN(t *testing.T) {
+	g := &gen{size: 17}
+	r, err := New(g, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	n, err := r.WriteN(&buf, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1000 || buf.Len() != 1000 {
+		t.Fatalf("got %d bytes (%d buffered), want 1000", n, buf.Len())
+	}
+	for i, c := range buf.Bytes() {
+		if c != byte(i) {
+			t.Fatalf("byte %d: got %d, want %d", i, c, byte(i))
+		}
+	}
+	if n, err := r.WriteN(&buf, 0); n != 0 || err != nil {
+		t.Errorf("WriteN(0) got %d, %v", n, err)
+	}
+}
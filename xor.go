@@ -0,0 +1,42 @@
+package cachedrander
+
+import "io"
+
+// An XORSource is a source that combines several independent sources by
+// exclusive oring their output together.  The output is unpredictable as long
+// as any one of the sources is, so it is useful when no single source is fully
+// trusted.  An XORSource is not safe for concurrent use.
+type XORSource struct {
+	sources []io.Reader
+	tmp     []byte
+}
+
+// NewXORSource returns an XORSource that combines sources.
+func NewXORSource(sources ...io.Reader) *XORSource {
+	return &XORSource{sources: sources}
+}
+
+// Read fills buf with the exclusive or of len(buf) bytes read from each
+// source.  It returns an error if any source does.
+func (x *XORSource) Read(buf []byte) (int, error) {
+	if len(x.sources) == 0 {
+		return 0, io.EOF
+	}
+	if _, err := io.ReadFull(x.sources[0], buf); err != nil {
+		return 0, err
+	}
+	if cap(x.tmp) < len(buf) {
+		x.tmp = make([]byte, len(buf))
+	}
+	tmp := x.tmp[:len(buf)]
+	defer clear(tmp)
+	for _, src := range x.sources[1:] {
+		if _, err := io.ReadFull(src, tmp); err != nil {
+			return 0, err
+		}
+		for i, b := range tmp {
+			buf[i] ^= b
+		}
+	}
+	return len(buf), nil
+}
@@ -0,0 +1,25 @@
+package cachedrander
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestXORSource(t *testing.T) {
+	a := bytes.NewReader([]byte{0x0f, 0xff, 0x00, 0x12})
+	b := bytes.NewReader([]byte{0xf0, 0xff, 0x00, 0x34})
+	c := bytes.NewReader([]byte{0x00, 0x00, 0x01, 0x56})
+	x := NewXORSource(a, b, c)
+	var buf [4]byte
+	if _, err := io.ReadFull(x, buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	want := [4]byte{0xff, 0x00, 0x01, 0x12 ^ 0x34 ^ 0x56}
+	if buf != want {
+		t.Errorf("got %x, want %x", buf, want)
+	}
+	if _, err := x.Read(buf[:]); err == nil {
+		t.Error("exhausted sources did not return an error")
+	}
+}
@@ -0,0 +1,11 @@
+package cachedrander
+
+// WithZeroizeOnRead causes each region of a page to be overwritten with zeros
+// as soon as it has been served, so a later disclosure of the process's memory
+// cannot reveal random data that was already used for keys, nonces, or IDs.
+// Data returned by Next is zeroed when it is released.
+func WithZeroizeOnRead() Option {
+	return func(r *CachedReader) {
+		r.zeroize = true
+	}
+}
@@ -0,0 +1,44 @@
+package cachedrander
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestZeroizeOnRead(t *testing.T) {
+	r, err := New(&gen{size: 256}, 128, WithZeroizeOnRead())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := r.cur.Load()
+	zeros := make([]byte, 48)
+
+	var buf [16]byte
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Read16(); err != nil {
+		t.Fatal(err)
+	}
+	b, release, err := r.Next(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(b, zeros[:16]) {
+		t.Error("Next returned zeroed data")
+	}
+	release()
+	if !bytes.Equal(p.data[:48], zeros) {
+		t.Errorf("served data not zeroed: %x", p.data[:48])
+	}
+	if p.data[48] != 48 {
+		t.Errorf("unserved data got %d, want 48", p.data[48])
+	}
+
+	r, err = New(&gen{size: 17}, 1024, WithZeroizeOnRead())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Max = 8
+	checkSequential(t, r)
+}